@@ -0,0 +1,245 @@
+// Package alerting evaluates alert rules against bot metrics and notifies operators
+// when a rule starts or stops firing.
+package alerting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dunamismax/discogo/logging"
+)
+
+// State is the state of an alert.
+type State string
+
+const (
+	// StateFiring means the rule condition is currently met.
+	StateFiring State = "firing"
+	// StateResolved means the rule condition is no longer met.
+	StateResolved State = "resolved"
+)
+
+// CheckFunc evaluates a rule at now. It reports whether the rule is firing and a
+// human-readable description of the observed value.
+type CheckFunc func(now time.Time) (firing bool, detail string)
+
+// Rule is a named alert condition.
+type Rule struct {
+	Name        string
+	Description string
+	Check       CheckFunc
+}
+
+// Alert is a notification about a rule changing state.
+type Alert struct {
+	Rule        string
+	Description string
+	State       State
+	Detail      string
+	StartedAt   time.Time
+	ResolvedAt  time.Time
+}
+
+// Duration returns how long the alert has been (or was) firing.
+func (a Alert) Duration() time.Duration {
+	if a.State == StateResolved {
+		return a.ResolvedAt.Sub(a.StartedAt)
+	}
+
+	return time.Since(a.StartedAt)
+}
+
+// Notifier delivers alerts to operators.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Options configures a Manager.
+type Options struct {
+	// Interval is how often rules are evaluated.
+	Interval time.Duration
+	// Cooldown is the minimum time between two firing notifications of the same rule,
+	// which keeps a flapping rule from flooding the channel. Zero disables the cooldown.
+	Cooldown time.Duration
+	// NotifyTimeout bounds the delivery of a single notification.
+	NotifyTimeout time.Duration
+	// OnStateChange, if set, is called whenever a rule starts or stops firing, including
+	// while its notifications are held back by the cooldown.
+	OnStateChange func(rule string, firing bool)
+}
+
+// Default manager settings.
+const (
+	defaultInterval      = 30 * time.Second
+	defaultCooldown      = 15 * time.Minute
+	defaultNotifyTimeout = 10 * time.Second
+)
+
+// ruleState tracks one rule between evaluations.
+type ruleState struct {
+	firing       bool
+	notified     bool // a firing notification was sent for the current episode.
+	startedAt    time.Time
+	lastNotified time.Time
+}
+
+// Manager periodically evaluates rules and sends deduplicated notifications.
+type Manager struct {
+	notifier Notifier
+	rules    []Rule
+	options  Options
+	states   map[string]*ruleState
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewManager creates an alert manager. Zero options fall back to defaults, except Cooldown,
+// which only does when negative since zero disables it.
+func NewManager(notifier Notifier, rules []Rule, options Options) *Manager {
+	if options.Interval <= 0 {
+		options.Interval = defaultInterval
+	}
+
+	if options.Cooldown < 0 {
+		options.Cooldown = defaultCooldown
+	}
+
+	if options.NotifyTimeout <= 0 {
+		options.NotifyTimeout = defaultNotifyTimeout
+	}
+
+	states := make(map[string]*ruleState, len(rules))
+	for _, rule := range rules {
+		states[rule.Name] = &ruleState{}
+	}
+
+	return &Manager{
+		notifier: notifier,
+		rules:    rules,
+		options:  options,
+		states:   states,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start begins evaluating rules in the background.
+func (m *Manager) Start() {
+	logger := logging.WithComponent("alerting")
+	logger.Info("Alerting started", "rules", len(m.rules), "interval", m.options.Interval)
+
+	m.wg.Add(1)
+
+	go m.run()
+}
+
+// Stop stops evaluating rules and waits for an in-flight evaluation to finish.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() { close(m.stop) })
+	m.wg.Wait()
+}
+
+func (m *Manager) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.evaluate(now)
+		}
+	}
+}
+
+// evaluate checks every rule once and sends notifications for state changes.
+//
+// A rule notifies once when it starts firing and once when it resolves. If it starts
+// firing again within the cooldown, the firing notification is held back until the
+// cooldown has passed; if the rule resolves before then, neither message is sent.
+func (m *Manager) evaluate(now time.Time) {
+	for _, rule := range m.rules {
+		state := m.states[rule.Name]
+		firing, detail := rule.Check(now)
+
+		switch {
+		case firing && !state.firing:
+			state.firing = true
+			state.notified = false
+			state.startedAt = now
+
+			m.stateChanged(rule.Name, true)
+
+			fallthrough
+		case firing && !state.notified:
+			if !state.lastNotified.IsZero() && now.Sub(state.lastNotified) < m.options.Cooldown {
+				continue
+			}
+
+			// A notification that could not be delivered is retried on the next evaluation.
+			err := m.notify(Alert{
+				Rule:        rule.Name,
+				Description: rule.Description,
+				State:       StateFiring,
+				Detail:      detail,
+				StartedAt:   state.startedAt,
+			})
+			if err != nil {
+				continue
+			}
+
+			state.notified = true
+			state.lastNotified = now
+		case !firing && state.firing:
+			state.firing = false
+
+			m.stateChanged(rule.Name, false)
+
+			if !state.notified {
+				continue
+			}
+
+			state.notified = false
+
+			_ = m.notify(Alert{
+				Rule:        rule.Name,
+				Description: rule.Description,
+				State:       StateResolved,
+				Detail:      detail,
+				StartedAt:   state.startedAt,
+				ResolvedAt:  now,
+			})
+		}
+	}
+}
+
+func (m *Manager) stateChanged(rule string, firing bool) {
+	if m.options.OnStateChange != nil {
+		m.options.OnStateChange(rule, firing)
+	}
+}
+
+// notify logs an alert and delivers it, returning the delivery error, which it logs too.
+func (m *Manager) notify(alert Alert) error {
+	logger := logging.WithComponent("alerting")
+
+	if alert.State == StateFiring {
+		logger.Warn("Alert firing", "rule", alert.Rule, "detail", alert.Detail)
+	} else {
+		logger.Info("Alert resolved", "rule", alert.Rule, "detail", alert.Detail)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.options.NotifyTimeout)
+	defer cancel()
+
+	err := m.notifier.Notify(ctx, alert)
+	if err != nil {
+		logger.Error("Failed to deliver alert", "rule", alert.Rule, "state", alert.State, "error", err)
+	}
+
+	return err
+}
@@ -0,0 +1,104 @@
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	botErrors "github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/metrics"
+)
+
+// CommandFailureRate fires when more than threshold percent of the commands run over window
+// failed. Windows with fewer than minCommands commands never fire, so a single failure
+// on a quiet bot does not page anyone.
+func CommandFailureRate(threshold float64, window time.Duration, minCommands int64) Rule {
+	return Rule{
+		Name:        "command_failure_rate",
+		Description: fmt.Sprintf("More than %.4g%% of commands failed over %s", threshold, window),
+		Check: func(_ time.Time) (bool, string) {
+			var total, failed int64
+
+			for _, point := range metrics.Get().GetHistory(window) {
+				total += point.Commands
+				failed += point.CommandsFailed
+			}
+
+			if total == 0 {
+				return false, fmt.Sprintf("No commands in the last %s", window)
+			}
+
+			rate := float64(failed) / float64(total) * 100
+			detail := fmt.Sprintf("%d of %d commands failed (%.1f%%) in the last %s", failed, total, rate, window)
+
+			return total >= minCommands && rate > threshold, detail
+		},
+	}
+}
+
+// ErrorSpike fires when at least threshold errors of errorType were recorded over window.
+func ErrorSpike(errorType botErrors.ErrorType, threshold int64, window time.Duration) Rule {
+	increase := newCounterIncrease(window)
+
+	return Rule{
+		Name:        string(errorType) + "_spike",
+		Description: fmt.Sprintf("At least %d %s errors within %s", threshold, errorType, window),
+		Check: func(now time.Time) (bool, string) {
+			count := increase.observe(now, metrics.Get().GetErrorCount(errorType))
+			detail := fmt.Sprintf("%d %s errors in the last %s", count, errorType, window)
+
+			return count >= threshold, detail
+		},
+	}
+}
+
+// GatewayDisconnected fires when the Discord gateway has been disconnected for longer than after.
+func GatewayDisconnected(after time.Duration) Rule {
+	return Rule{
+		Name:        "gateway_disconnected",
+		Description: fmt.Sprintf("Discord gateway disconnected for more than %s", after),
+		Check: func(now time.Time) (bool, string) {
+			connected, since := metrics.Get().GetGatewayStatus()
+			if connected {
+				return false, fmt.Sprintf("Gateway connected since %s", since.Format(time.RFC3339))
+			}
+
+			down := now.Sub(since).Truncate(time.Second)
+
+			return down > after, fmt.Sprintf("Gateway disconnected for %s", down)
+		},
+	}
+}
+
+// counterSample is a cumulative counter value observed at a point in time.
+type counterSample struct {
+	at    time.Time
+	value int64
+}
+
+// counterIncrease turns a cumulative counter into its increase over a sliding window.
+type counterIncrease struct {
+	window  time.Duration
+	samples []counterSample
+}
+
+func newCounterIncrease(window time.Duration) *counterIncrease {
+	return &counterIncrease{window: window}
+}
+
+// observe records value at now and returns how much the counter grew over the window.
+// Until a full window has been observed the increase is measured from the first sample.
+func (ci *counterIncrease) observe(now time.Time, value int64) int64 {
+	ci.samples = append(ci.samples, counterSample{at: now, value: value})
+
+	// Keep the newest sample at or before the window start as the baseline.
+	start := now.Add(-ci.window)
+	drop := 0
+
+	for drop+1 < len(ci.samples) && !ci.samples[drop+1].at.After(start) {
+		drop++
+	}
+
+	ci.samples = ci.samples[drop:]
+
+	return value - ci.samples[0].value
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dunamismax/discogo/archive"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/logging"
+	"github.com/dunamismax/discogo/storage"
+)
+
+// archiveUsage describes the export and import commands.
+const archiveUsage = `usage: discord-bot [flags] export <guild-id> <file>
+       discord-bot [flags] import <guild-id> <file> [apply]
+
+export writes everything stored for the guild to a JSON archive. import shows what
+importing the archive would change, and replaces the guild's data with it when apply is
+given. Stop the bot before importing.`
+
+// runArchive runs the export and import commands, which copy a guild's data to and from
+// JSON archives without connecting to Discord, and returns the exit code.
+func runArchive(cfg *config.Config, command string, args []string) int {
+	switch {
+	case command == "export" && len(args) == 2:
+	case command == "import" && len(args) == 2:
+	case command == "import" && len(args) == 3 && args[2] == "apply":
+	default:
+		fmt.Fprintln(os.Stderr, archiveUsage)
+		return 2
+	}
+
+	if !config.IsSnowflake(args[0]) {
+		fmt.Fprintf(os.Stderr, "invalid guild ID %q (expected a Discord ID)\n", args[0])
+		return 2
+	}
+
+	store, err := storage.Open(storage.Config{Backend: cfg.StorageBackend, Path: cfg.StoragePath})
+	if err != nil {
+		logging.Error("Failed to open storage", "error", err)
+		return 1
+	}
+
+	defer func() {
+		if err := store.Close(); err != nil {
+			logging.Error("Error closing storage", "error", err)
+		}
+	}()
+
+	if command == "export" {
+		return exportArchive(store, args)
+	}
+
+	return importArchive(store, args)
+}
+
+// exportArchive writes the archive of a guild to a file.
+func exportArchive(store storage.Store, args []string) int {
+	exported, err := archive.Export(store, args[0])
+	if err != nil {
+		logging.Error("Failed to export guild data", "error", err)
+		return 1
+	}
+
+	data, err := exported.Marshal()
+	if err != nil {
+		logging.Error("Failed to export guild data", "error", err)
+		return 1
+	}
+
+	if err := os.WriteFile(args[1], data, 0o600); err != nil {
+		logging.Error("Failed to write archive", "error", err)
+		return 1
+	}
+
+	logging.Info("Exported guild data", "guild_id", args[0], "features", len(exported.Features), "file", args[1])
+
+	return 0
+}
+
+// importArchive previews or applies the import of an archive file into a guild.
+func importArchive(store storage.Store, args []string) int {
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		logging.Error("Failed to read archive", "error", err)
+		return 1
+	}
+
+	imported, err := archive.Parse(data)
+	if err != nil {
+		logging.Error("Invalid archive", "error", err)
+		return 1
+	}
+
+	apply := len(args) == 3
+
+	var changes []archive.Change
+	if apply {
+		changes, err = archive.Import(store, args[0], imported)
+	} else {
+		changes, err = archive.Plan(store, args[0], imported)
+	}
+
+	if err != nil {
+		logging.Error("Failed to import archive", "error", err)
+		return 1
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("The archive matches the stored data; nothing changes.")
+		return 0
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CHANGE\tKEY\tOLD\tNEW")
+
+	for _, change := range changes {
+		fmt.Fprintf(writer, "%s\t%s.%s\t%s\t%s\n", change.Kind, change.Feature, change.Key, change.Old, change.New)
+	}
+
+	_ = writer.Flush()
+
+	if apply {
+		fmt.Printf("\n%d changes applied.\n", len(changes))
+	} else {
+		fmt.Printf("\n%d changes; nothing was written. Add apply to import the archive.\n", len(changes))
+	}
+
+	return 0
+}
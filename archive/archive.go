@@ -0,0 +1,428 @@
+// Package archive exports everything stored for a guild into a versioned JSON archive, and
+// imports such an archive back. Server administrators use it to back up or move their
+// server's data, and to get a copy of the data the bot keeps about their server.
+//
+// An archive holds every guild namespace of the storage, grouped by feature, so features
+// that keep their data under guild namespaces are included without changes here. Imports
+// are validated before anything is written: features must be known, and their values must
+// pass the feature's own validation.
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dunamismax/discogo/blocklist"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/rules"
+	"github.com/dunamismax/discogo/settings"
+	"github.com/dunamismax/discogo/storage"
+)
+
+// Version is the version of the archive format written by Export.
+const Version = 1
+
+// MaxSize is the size of the largest archive Parse accepts.
+const MaxSize = 1 << 20
+
+// maxProblems is the number of validation problems reported for an invalid archive.
+const maxProblems = 10
+
+// Archive is the data stored for a guild.
+type Archive struct {
+	// Version is the version of the archive format.
+	Version int `json:"version"`
+	// GuildID is the guild the data was exported from.
+	GuildID string `json:"guild_id"`
+	// ExportedAt is when the archive was created.
+	ExportedAt time.Time `json:"exported_at"`
+	// SchemaVersion is the storage schema version the data was exported from.
+	SchemaVersion int `json:"schema_version"`
+	// Features maps feature names to the keys and values of the guild's namespace.
+	Features map[string]map[string]Value `json:"features"`
+}
+
+// Value is a stored value. JSON documents are archived as they are, so they stay readable;
+// other values are archived as strings.
+type Value []byte
+
+// MarshalJSON implements json.Marshaler.
+func (v Value) MarshalJSON() ([]byte, error) {
+	trimmed := bytes.TrimSpace(v)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, trimmed); err != nil {
+			return nil, err
+		}
+
+		return compact.Bytes(), nil
+	}
+
+	return json.Marshal(string(v))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return fmt.Errorf("empty value")
+	}
+
+	switch trimmed[0] {
+	case '"':
+		var text string
+		if err := json.Unmarshal(trimmed, &text); err != nil {
+			return err
+		}
+
+		*v = Value(text)
+	case '{', '[':
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, trimmed); err != nil {
+			return err
+		}
+
+		*v = compact.Bytes()
+	default:
+		return fmt.Errorf("value must be a string, an object or an array")
+	}
+
+	return nil
+}
+
+// validator checks a value of a feature and returns the value to store.
+type validator func(key string, value []byte) ([]byte, error)
+
+// validators holds the validation of every feature that can be imported.
+var validators = map[string]validator{
+	settings.Feature: func(key string, value []byte) ([]byte, error) {
+		normalized, err := settings.Normalize(key, string(value))
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(normalized), nil
+	},
+	rules.Feature:     rules.Validate,
+	blocklist.Feature: blocklist.Validate,
+}
+
+// retargets holds the features whose values record the guild they belong to, and moves such
+// values to the guild an archive is imported into.
+var retargets = map[string]func(value []byte, guildID string) ([]byte, error){
+	blocklist.Feature: blocklist.Retarget,
+}
+
+// Export reads everything stored for a guild.
+func Export(store storage.Store, guildID string) (*Archive, error) {
+	if err := checkGuild(guildID); err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{
+		Version:    Version,
+		GuildID:    guildID,
+		ExportedAt: time.Now().UTC(),
+		Features:   make(map[string]map[string]Value),
+	}
+
+	err := store.View(func(tx storage.Tx) error {
+		var err error
+		if archive.SchemaVersion, err = storage.SchemaVersion(tx); err != nil {
+			return err
+		}
+
+		data, err := read(tx, guildID)
+		if err != nil {
+			return err
+		}
+
+		for feature, values := range data {
+			archive.Features[feature] = make(map[string]Value, len(values))
+			for key, value := range values {
+				archive.Features[feature][key] = Value(value)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to export guild data", err)
+	}
+
+	return archive, nil
+}
+
+// Marshal encodes an archive as indented JSON.
+func (a *Archive) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, errors.NewInternalError("failed to encode archive", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// Parse decodes and validates an archive. Values are replaced by the form the features
+// store, so the archive can be compared with the storage and imported. Problems are
+// reported as a validation error, which i18n.Localizer.Error translates for the user.
+func Parse(data []byte) (*Archive, error) {
+	if len(data) > MaxSize {
+		return nil, i18n.ValidationError("archive.too_large", MaxSize>>10)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var archive Archive
+	if err := decoder.Decode(&archive); err != nil {
+		return nil, i18n.ValidationError("archive.unreadable", err.Error())
+	}
+
+	if archive.Version != Version {
+		return nil, i18n.ValidationError("archive.unsupported_version", archive.Version, Version)
+	}
+
+	var problems []error
+
+	for feature, values := range archive.Features {
+		validate, ok := validators[feature]
+		if !ok {
+			problems = append(problems, i18n.ValidationError("archive.unknown_feature", feature))
+			continue
+		}
+
+		for key, value := range values {
+			normalized, err := validate(key, value)
+			if err != nil {
+				problems = append(problems, i18n.ValidationError("archive.problem", feature, key, err))
+				continue
+			}
+
+			values[key] = normalized
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Slice(problems, func(i, j int) bool {
+			return problems[i].Error() < problems[j].Error()
+		})
+
+		if len(problems) > maxProblems {
+			problems = append(problems[:maxProblems], i18n.ValidationError("archive.more_problems", len(problems)-maxProblems))
+		}
+
+		return nil, i18n.ValidationError("archive.invalid", problems)
+	}
+
+	return &archive, nil
+}
+
+// ChangeKind tells how an import changes a stored value.
+type ChangeKind string
+
+// Kinds of changes.
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeChanged ChangeKind = "changed"
+	ChangeRemoved ChangeKind = "removed"
+)
+
+// Change is a difference between the storage and an archive.
+type Change struct {
+	Kind    ChangeKind
+	Feature string
+	Key     string
+	// Old is the stored value; empty for added values.
+	Old string
+	// New is the archived value; empty for removed values.
+	New string
+}
+
+// Plan returns the changes importing the archive into a guild would make, without making
+// them. Importing replaces everything stored for the guild.
+func Plan(store storage.Store, guildID string, archive *Archive) ([]Change, error) {
+	if err := checkGuild(guildID); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+
+	err := store.View(func(tx storage.Tx) error {
+		var err error
+		changes, err = plan(tx, guildID, archive)
+
+		return err
+	})
+	if err != nil {
+		return nil, wrap("failed to compare archive", err)
+	}
+
+	return changes, nil
+}
+
+// Import replaces everything stored for a guild with the contents of the archive, in one
+// transaction, and returns the changes it made. Callers must drop any cached copy of the
+// guild's data afterwards, such as with settings.Store.Invalidate.
+func Import(store storage.Store, guildID string, archive *Archive) ([]Change, error) {
+	if err := checkGuild(guildID); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+
+	err := store.Update(func(tx storage.Tx) error {
+		var err error
+		if changes, err = plan(tx, guildID, archive); err != nil {
+			return err
+		}
+
+		namespaces, err := tx.Namespaces()
+		if err != nil {
+			return err
+		}
+
+		for _, ns := range namespaces {
+			if ns.GuildID() == guildID {
+				if err := tx.DeleteNamespace(ns); err != nil {
+					return err
+				}
+			}
+		}
+
+		for feature, values := range archive.Features {
+			bucket := tx.Bucket(storage.GuildNamespace(feature, guildID))
+			for key, value := range values {
+				if err := bucket.Put(key, value); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, wrap("failed to import archive", err)
+	}
+
+	return changes, nil
+}
+
+// plan compares the guild's data in tx with the archive. It refuses archives exported from
+// another storage schema: data of a newer schema may not be understood by this version,
+// and archives are not migrated, so data of an older schema would be stored in a shape the
+// storage's migrations have already changed. Values that record their guild are moved to
+// guildID in the archive, so the archive of one guild can be imported into another.
+func plan(tx storage.Tx, guildID string, archive *Archive) ([]Change, error) {
+	version, err := storage.SchemaVersion(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if archive.SchemaVersion != version {
+		return nil, i18n.ValidationError("archive.schema_mismatch", archive.SchemaVersion, version)
+	}
+
+	for feature, retarget := range retargets {
+		for key, value := range archive.Features[feature] {
+			if archive.Features[feature][key], err = retarget(value, guildID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	stored, err := read(tx, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+
+	for feature, values := range archive.Features {
+		for key, value := range values {
+			old, ok := stored[feature][key]
+
+			switch {
+			case !ok:
+				changes = append(changes, Change{Kind: ChangeAdded, Feature: feature, Key: key, New: string(value)})
+			case !bytes.Equal(old, value):
+				changes = append(changes, Change{Kind: ChangeChanged, Feature: feature, Key: key,
+					Old: string(old), New: string(value)})
+			}
+		}
+	}
+
+	for feature, values := range stored {
+		for key, value := range values {
+			if _, ok := archive.Features[feature][key]; !ok {
+				changes = append(changes, Change{Kind: ChangeRemoved, Feature: feature, Key: key, Old: string(value)})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Feature != changes[j].Feature {
+			return changes[i].Feature < changes[j].Feature
+		}
+
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes, nil
+}
+
+// read returns the keys and values of every namespace of a guild, by feature.
+func read(tx storage.Tx, guildID string) (map[string]map[string][]byte, error) {
+	namespaces, err := tx.Namespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]map[string][]byte)
+
+	for _, ns := range namespaces {
+		if ns.GuildID() != guildID {
+			continue
+		}
+
+		values := make(map[string][]byte)
+
+		err := tx.Bucket(ns).Scan("", func(key string, value []byte) error {
+			values[key] = value
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(values) > 0 {
+			data[ns.Feature()] = values
+		}
+	}
+
+	return data, nil
+}
+
+// checkGuild rejects guild IDs that are not Discord IDs. An empty ID in particular would
+// select the global namespaces, which belong to no guild.
+func checkGuild(guildID string) error {
+	if !config.IsSnowflake(guildID) {
+		return i18n.ValidationError("archive.invalid_guild", guildID)
+	}
+
+	return nil
+}
+
+// wrap returns validation errors as they are, so they reach the user, and wraps the others.
+func wrap(message string, err error) error {
+	if errors.IsErrorType(err, errors.ErrorTypeValidation) {
+		return err
+	}
+
+	return errors.NewInternalError(message, err)
+}
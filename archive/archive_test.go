@@ -0,0 +1,206 @@
+package archive
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dunamismax/discogo/blocklist"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/rules"
+	"github.com/dunamismax/discogo/settings"
+	"github.com/dunamismax/discogo/storage"
+)
+
+const (
+	guildID      = "111111111111111111"
+	otherGuildID = "222222222222222222"
+	channelID    = "333333333333333333"
+	userID       = "444444444444444444"
+)
+
+// populate stores data of every importable feature for a guild.
+func populate(t *testing.T, store storage.Store, guildID string) {
+	t.Helper()
+
+	defaults := func() *config.Config { return &config.Config{CommandPrefix: "!"} }
+	if _, err := settings.NewStore(store, defaults).Set(guildID, "prefix", "?"); err != nil {
+		t.Fatalf("failed to store setting: %v", err)
+	}
+
+	rule := rules.Rule{ChannelID: channelID, Target: "ping", Effect: rules.Deny}
+	if err := rules.NewStore(store).Set(guildID, rule); err != nil {
+		t.Fatalf("failed to store rule: %v", err)
+	}
+
+	entry := blocklist.Entry{Kind: blocklist.KindUser, ID: userID, GuildID: guildID, AddedAt: time.Now().UTC()}
+	if err := blocklist.NewStore(store).Add(entry); err != nil {
+		t.Fatalf("failed to store blocklist entry: %v", err)
+	}
+}
+
+// roundTrip exports a guild, encodes the archive and parses it back, as an import does.
+func roundTrip(t *testing.T, store storage.Store, guildID string) *Archive {
+	t.Helper()
+
+	exported, err := Export(store, guildID)
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+
+	data, err := exported.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	return parsed
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := storage.NewMemory()
+	populate(t, source, guildID)
+
+	archived := roundTrip(t, source, guildID)
+
+	for _, feature := range []string{settings.Feature, rules.Feature, blocklist.Feature} {
+		if len(archived.Features[feature]) == 0 {
+			t.Errorf("archive has no %s values", feature)
+		}
+	}
+
+	target := storage.NewMemory()
+
+	changes, err := Import(target, guildID, archived)
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	if len(changes) != 3 {
+		t.Errorf("Import() made %d changes, want 3: %v", len(changes), changes)
+	}
+
+	imported := roundTrip(t, target, guildID)
+	if !reflect.DeepEqual(imported.Features, archived.Features) {
+		t.Errorf("imported data = %v, want %v", imported.Features, archived.Features)
+	}
+
+	changes, err = Plan(target, guildID, archived)
+	if err != nil {
+		t.Fatalf("Plan() failed: %v", err)
+	}
+
+	if len(changes) != 0 {
+		t.Errorf("Plan() after importing = %v, want no changes", changes)
+	}
+}
+
+func TestImportReplacesGuildData(t *testing.T) {
+	store := storage.NewMemory()
+	populate(t, store, guildID)
+	populate(t, store, otherGuildID)
+
+	empty := &Archive{Version: Version, GuildID: guildID}
+
+	changes, err := Import(store, guildID, empty)
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	for _, change := range changes {
+		if change.Kind != ChangeRemoved {
+			t.Errorf("importing an empty archive made change %+v, want only removals", change)
+		}
+	}
+
+	if remaining := roundTrip(t, store, guildID); len(remaining.Features) != 0 {
+		t.Errorf("guild data after importing an empty archive = %v, want none", remaining.Features)
+	}
+
+	if other := roundTrip(t, store, otherGuildID); len(other.Features) != 3 {
+		t.Errorf("other guild has %d features after the import, want 3", len(other.Features))
+	}
+}
+
+func TestImportMovesBlocklistToTargetGuild(t *testing.T) {
+	source := storage.NewMemory()
+	populate(t, source, guildID)
+
+	target := storage.NewMemory()
+	if _, err := Import(target, otherGuildID, roundTrip(t, source, guildID)); err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+
+	entry, blocked := blocklist.NewStore(target).Blocked(otherGuildID, userID)
+	if !blocked {
+		t.Fatal("imported blocklist entry does not block the user in the target guild")
+	}
+
+	if entry.GuildID != otherGuildID {
+		t.Errorf("imported entry has guild %q, want %q", entry.GuildID, otherGuildID)
+	}
+}
+
+func TestImportRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		guildID string
+		archive func(a *Archive)
+	}{
+		{name: "empty guild ID", guildID: ""},
+		{name: "invalid guild ID", guildID: "general"},
+		{name: "other schema version", guildID: guildID, archive: func(a *Archive) { a.SchemaVersion++ }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemory()
+			populate(t, store, guildID)
+
+			archived := roundTrip(t, store, guildID)
+			if tt.archive != nil {
+				tt.archive(archived)
+			}
+
+			before := roundTrip(t, store, guildID)
+
+			_, err := Import(store, tt.guildID, archived)
+			if !errors.IsErrorType(err, errors.ErrorTypeValidation) {
+				t.Fatalf("Import() = %v, want a validation error", err)
+			}
+
+			if after := roundTrip(t, store, guildID); !reflect.DeepEqual(after.Features, before.Features) {
+				t.Errorf("rejected import changed the data from %v to %v", before.Features, after.Features)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "unknown version", data: `{"version": 2}`},
+		{name: "unknown field", data: `{"version": 1, "extra": true}`},
+		{name: "unknown feature", data: `{"version": 1, "features": {"bogus": {"key": "value"}}}`},
+		{name: "invalid setting", data: `{"version": 1, "features": {"settings": {"prefix": "a b"}}}`},
+		{name: "unknown setting", data: `{"version": 1, "features": {"settings": {"bogus": "on"}}}`},
+		{name: "invalid rule", data: `{"version": 1, "features": {"command_rules": {"/ping": "maybe"}}}`},
+		{name: "invalid blocklist entry", data: `{"version": 1, "features": {"blocklist": {"user/1": "{}"}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if !errors.IsErrorType(err, errors.ErrorTypeValidation) {
+				t.Errorf("Parse() = %v, want a validation error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+// Package blocklist keeps the users and guilds the bot ignores. The global blocklist is
+// managed by the bot owners and can block users and whole guilds; each guild also has its
+// own blocklist of users, managed by its administrators. Blocks can be permanent or expire.
+//
+// Detector counts the strikes of users, such as denied commands and cooldown hits, and
+// tells when a user earned a temporary block.
+package blocklist
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/storage"
+)
+
+// Feature is the storage feature name of the blocklists. The global blocklist is kept in
+// the feature namespace and each guild's in its own namespace.
+const Feature = "blocklist"
+
+// Kind is the kind of a blocked ID.
+type Kind string
+
+// Kinds of blocked IDs.
+const (
+	KindUser  Kind = "user"
+	KindGuild Kind = "guild"
+)
+
+// Entry is a block.
+type Entry struct {
+	Kind Kind   `json:"kind"`
+	ID   string `json:"id"`
+	// GuildID is the guild whose blocklist holds the entry; empty for the global blocklist.
+	GuildID string `json:"guild_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	// AddedBy is the user who added the entry; empty for automatic blocks.
+	AddedBy string    `json:"added_by,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+	// ExpiresAt is when the block ends; zero for permanent blocks.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the block has ended at the given time.
+func (e Entry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// key returns the storage key of the entry.
+func (e Entry) key() string {
+	return entryKey(e.Kind, e.ID)
+}
+
+// entryKey returns the storage key of a blocked ID.
+func entryKey(kind Kind, id string) string {
+	return string(kind) + "/" + id
+}
+
+var entries = storage.NewCollection[Entry](Feature)
+
+// Store reads and writes the blocklists. It caches every blocklist it has read, since they
+// are checked for every command. It is safe for concurrent use.
+type Store struct {
+	store storage.Store
+
+	mutex sync.RWMutex
+	// cache maps guild IDs, or "" for the global blocklist, to entries by key.
+	cache map[string]map[string]Entry
+	// generation counts writes, so a load that raced with a write is not cached.
+	generation uint64
+}
+
+// NewStore creates a blocklist store.
+func NewStore(store storage.Store) *Store {
+	return &Store{
+		store: store,
+		cache: make(map[string]map[string]Entry),
+	}
+}
+
+// Blocked returns the block that applies to a user in a guild, if any: a global block of
+// the user or the guild, or a block of the user in the guild. guildID is empty for direct
+// messages. When the blocklists cannot be read, nothing is blocked, so the bot keeps working.
+func (s *Store) Blocked(guildID, userID string) (Entry, bool) {
+	now := time.Now()
+
+	global, err := s.list("")
+	if err != nil {
+		return Entry{}, false
+	}
+
+	candidates := []Entry{global[entryKey(KindUser, userID)]}
+
+	if guildID != "" {
+		candidates = append(candidates, global[entryKey(KindGuild, guildID)])
+
+		if local, err := s.list(guildID); err == nil {
+			candidates = append(candidates, local[entryKey(KindUser, userID)])
+		}
+	}
+
+	for _, entry := range candidates {
+		if entry.ID != "" && !entry.Expired(now) {
+			return entry, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// List returns the active entries of a blocklist, guildID being empty for the global one,
+// sorted by kind and ID.
+func (s *Store) List(guildID string) ([]Entry, error) {
+	cached, err := s.list(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := make([]Entry, 0, len(cached))
+
+	for _, entry := range cached {
+		if !entry.Expired(now) {
+			list = append(list, entry)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].key() < list[j].key()
+	})
+
+	return list, nil
+}
+
+// Add adds an entry to the blocklist of entry.GuildID, replacing any entry for the same ID.
+// Expired entries of the blocklist are removed at the same time.
+func (s *Store) Add(entry Entry) error {
+	if entry.Kind == KindGuild && entry.GuildID != "" {
+		return i18n.ValidationError("blocklist.guild_global")
+	}
+
+	now := time.Now()
+
+	err := s.store.Update(func(tx storage.Tx) error {
+		var expired []string
+
+		err := entries.ForEach(tx, entry.GuildID, "", func(key string, existing Entry) error {
+			if existing.Expired(now) {
+				expired = append(expired, key)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := entries.Delete(tx, entry.GuildID, key); err != nil {
+				return err
+			}
+		}
+
+		return entries.Put(tx, entry.GuildID, entry.key(), entry)
+	})
+	if err != nil {
+		return errors.NewInternalError("failed to save blocklist", err)
+	}
+
+	s.Invalidate(entry.GuildID)
+
+	return nil
+}
+
+// Remove removes the entry for an ID from the blocklist of a guild, or the global one when
+// guildID is empty. It reports whether there was an active entry.
+func (s *Store) Remove(guildID string, kind Kind, id string) (bool, error) {
+	removed := false
+
+	err := s.store.Update(func(tx storage.Tx) error {
+		entry, err := entries.Get(tx, guildID, entryKey(kind, id))
+		if err == storage.ErrNotFound {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		removed = !entry.Expired(time.Now())
+
+		return entries.Delete(tx, guildID, entryKey(kind, id))
+	})
+	if err != nil {
+		return false, errors.NewInternalError("failed to save blocklist", err)
+	}
+
+	s.Invalidate(guildID)
+
+	return removed, nil
+}
+
+// list returns the entries of a blocklist by key, reading them from storage on first use.
+func (s *Store) list(guildID string) (map[string]Entry, error) {
+	s.mutex.RLock()
+	cached, ok := s.cache[guildID]
+	generation := s.generation
+	s.mutex.RUnlock()
+
+	if ok {
+		return cached, nil
+	}
+
+	cached = make(map[string]Entry)
+
+	err := s.store.View(func(tx storage.Tx) error {
+		return entries.ForEach(tx, guildID, "", func(key string, entry Entry) error {
+			cached[key] = entry
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to load blocklist", err)
+	}
+
+	s.mutex.Lock()
+	if s.generation == generation {
+		s.cache[guildID] = cached
+	}
+	s.mutex.Unlock()
+
+	return cached, nil
+}
+
+// Invalidate drops the cached copy of a blocklist. Call it after changing a guild's
+// blocklist in storage without the Store, for example when importing a backup.
+func (s *Store) Invalidate(guildID string) {
+	s.mutex.Lock()
+	delete(s.cache, guildID)
+	s.generation++
+	s.mutex.Unlock()
+}
+
+// detectorSweepSize is the number of users with strikes above which Detector first drops
+// the users without recent strikes.
+const detectorSweepSize = 1024
+
+// Detector counts the strikes of users within a sliding window. It is safe for concurrent use.
+type Detector struct {
+	mutex   sync.Mutex
+	strikes map[string][]time.Time
+	// sweepAt is the number of users with strikes at which the next sweep happens.
+	sweepAt int
+}
+
+// NewDetector creates a detector.
+func NewDetector() *Detector {
+	return &Detector{strikes: make(map[string][]time.Time), sweepAt: detectorSweepSize}
+}
+
+// Strike records a strike of a user and reports whether the user reached limit strikes
+// within window. The strikes of a user who reached the limit are forgotten, so the next
+// block needs limit new strikes. A limit of 0 disables detection.
+func (d *Detector) Strike(userID string, limit int, window time.Duration) bool {
+	if limit <= 0 {
+		return false
+	}
+
+	now := time.Now()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	recent := d.strikes[userID][:0]
+
+	for _, strike := range d.strikes[userID] {
+		if now.Sub(strike) < window {
+			recent = append(recent, strike)
+		}
+	}
+
+	recent = append(recent, now)
+
+	if len(recent) >= limit {
+		delete(d.strikes, userID)
+		return true
+	}
+
+	d.strikes[userID] = recent
+
+	// Drop users without recent strikes once the map grew, so it does not grow without
+	// bound. The next sweep waits until the map doubled, so a map of users who all have
+	// recent strikes is not swept on every strike.
+	if len(d.strikes) >= d.sweepAt {
+		for id, strikes := range d.strikes {
+			if now.Sub(strikes[len(strikes)-1]) >= window {
+				delete(d.strikes, id)
+			}
+		}
+
+		d.sweepAt = max(detectorSweepSize, 2*len(d.strikes))
+	}
+
+	return false
+}
+
+// Validate checks a stored entry of a guild blocklist, such as one from an imported
+// archive, and returns the value to store.
+func Validate(key string, value []byte) ([]byte, error) {
+	var entry Entry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return nil, i18n.ValidationError("blocklist.invalid_entry", err.Error())
+	}
+
+	if entry.Kind != KindUser || entry.ID == "" || key != entry.key() {
+		return nil, i18n.ValidationError("blocklist.invalid_key", key)
+	}
+
+	return value, nil
+}
+
+// Retarget returns a validated entry of a guild blocklist moved to the blocklist of guildID,
+// for entries imported from the archive of another guild.
+func Retarget(value []byte, guildID string) ([]byte, error) {
+	var entry Entry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return nil, i18n.ValidationError("blocklist.invalid_entry", err.Error())
+	}
+
+	if entry.GuildID == guildID {
+		return value, nil
+	}
+
+	entry.GuildID = guildID
+
+	retargeted, err := json.Marshal(entry)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to encode blocklist entry", err)
+	}
+
+	return retargeted, nil
+}
+
+// ParseKind returns the kind with the given name, or a validation error.
+func ParseKind(name string) (Kind, error) {
+	switch Kind(strings.ToLower(name)) {
+	case KindUser:
+		return KindUser, nil
+	case KindGuild:
+		return KindGuild, nil
+	default:
+		return "", i18n.ValidationError("blocklist.unknown_kind", name)
+	}
+}
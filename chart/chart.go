@@ -0,0 +1,280 @@
+// Package chart renders simple time series line charts to PNG images.
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Layout constants, in pixels.
+const (
+	defaultWidth       = 800
+	defaultPanelHeight = 160
+	titleHeight        = 28
+	panelTitleHeight   = 18
+	axisLabelHeight    = 18
+	marginLeft         = 56
+	marginRight        = 16
+	panelSpacing       = 10
+	gridLines          = 4
+)
+
+// Colors used for chart elements.
+var (
+	backgroundColor = color.RGBA{R: 0x2F, G: 0x31, B: 0x36, A: 0xFF}
+	plotColor       = color.RGBA{R: 0x36, G: 0x39, B: 0x3F, A: 0xFF}
+	gridColor       = color.RGBA{R: 0x4F, G: 0x54, B: 0x5C, A: 0xFF}
+	textColor       = color.RGBA{R: 0xDC, G: 0xDD, B: 0xDE, A: 0xFF}
+	mutedTextColor  = color.RGBA{R: 0x96, G: 0x98, B: 0x9D, A: 0xFF}
+)
+
+// Panel is a single series drawn with its own vertical scale.
+type Panel struct {
+	Title  string
+	Unit   string
+	Color  color.RGBA
+	Values []float64
+}
+
+// Chart is a set of panels sharing a time axis.
+type Chart struct {
+	Title       string
+	Width       int
+	PanelHeight int
+	Start       time.Time
+	End         time.Time
+	Location    *time.Location
+	Panels      []Panel
+}
+
+// Render draws the chart and encodes it as PNG to w.
+func (c *Chart) Render(w io.Writer) error {
+	if len(c.Panels) == 0 {
+		return fmt.Errorf("chart has no panels")
+	}
+
+	width := c.Width
+	if width <= 0 {
+		width = defaultWidth
+	}
+
+	panelHeight := c.PanelHeight
+	if panelHeight <= 0 {
+		panelHeight = defaultPanelHeight
+	}
+
+	height := titleHeight + len(c.Panels)*(panelTitleHeight+panelHeight+panelSpacing) + axisLabelHeight
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+
+	drawText(img, c.Title, marginLeft, titleHeight-10, textColor)
+
+	top := titleHeight
+	for _, panel := range c.Panels {
+		plot := image.Rect(marginLeft, top+panelTitleHeight, width-marginRight, top+panelTitleHeight+panelHeight)
+		c.drawPanel(img, panel, plot)
+
+		top = plot.Max.Y + panelSpacing
+	}
+
+	c.drawTimeAxis(img, top)
+
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("failed to encode chart: %w", err)
+	}
+
+	return nil
+}
+
+// drawPanel draws one panel into the plot rectangle.
+func (c *Chart) drawPanel(img *image.RGBA, panel Panel, plot image.Rectangle) {
+	draw.Draw(img, plot, image.NewUniform(plotColor), image.Point{}, draw.Src)
+
+	maxValue := 0.0
+	for _, value := range panel.Values {
+		if !math.IsNaN(value) && value > maxValue {
+			maxValue = value
+		}
+	}
+
+	scale := niceCeiling(maxValue)
+
+	title := panel.Title
+	if panel.Unit != "" {
+		title = fmt.Sprintf("%s (%s)", panel.Title, panel.Unit)
+	}
+
+	drawText(img, title, plot.Min.X, plot.Min.Y-5, textColor)
+
+	// Horizontal grid lines with their values.
+	for i := 0; i <= gridLines; i++ {
+		y := plot.Max.Y - (plot.Dy()-1)*i/gridLines
+		drawHorizontalLine(img, plot.Min.X, plot.Max.X, y, gridColor)
+
+		label := formatValue(scale * float64(i) / gridLines)
+		drawText(img, label, plot.Min.X-6-textWidth(label), y+4, mutedTextColor)
+	}
+
+	// Plot the series as connected line segments; NaN values are skipped and
+	// the line joins the surrounding points.
+	var previous image.Point
+
+	hasPrevious := false
+
+	for i, value := range panel.Values {
+		if math.IsNaN(value) {
+			continue
+		}
+
+		x := plot.Min.X
+		if len(panel.Values) > 1 {
+			x += (plot.Dx() - 1) * i / (len(panel.Values) - 1)
+		}
+
+		y := plot.Max.Y - 1 - int(math.Round(math.Max(0, value)/scale*float64(plot.Dy()-1)))
+		current := image.Point{X: x, Y: y}
+
+		if !hasPrevious {
+			previous = current
+		}
+
+		drawLine(img, previous, current, panel.Color)
+		drawLine(img, previous.Add(image.Point{Y: -1}), current.Add(image.Point{Y: -1}), panel.Color)
+
+		previous, hasPrevious = current, true
+	}
+}
+
+// drawTimeAxis labels the start, middle and end of the shared time axis.
+func (c *Chart) drawTimeAxis(img *image.RGBA, top int) {
+	if c.Start.IsZero() || c.End.IsZero() {
+		return
+	}
+
+	location := c.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	layout := "15:04"
+	if c.End.Sub(c.Start) > 24*time.Hour {
+		layout = "Jan 2 15:04"
+	}
+
+	right := img.Bounds().Dx() - marginRight
+	y := top + axisLabelHeight/2
+
+	startLabel := c.Start.In(location).Format(layout)
+	middleLabel := c.Start.Add(c.End.Sub(c.Start) / 2).In(location).Format(layout)
+	endLabel := c.End.In(location).Format(layout) + " " + c.End.In(location).Format("MST")
+
+	drawText(img, startLabel, marginLeft, y, mutedTextColor)
+	drawText(img, middleLabel, (marginLeft+right)/2-textWidth(middleLabel)/2, y, mutedTextColor)
+	drawText(img, endLabel, right-textWidth(endLabel), y, mutedTextColor)
+}
+
+// niceCeiling rounds value up to 1, 2 or 5 times a power of ten, so grid labels stay readable.
+func niceCeiling(value float64) float64 {
+	if value <= 0 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(value)))
+
+	for _, step := range []float64{1, 2, 5, 10} {
+		if value <= step*magnitude {
+			return step * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
+
+// formatValue formats a grid label compactly.
+func formatValue(value float64) string {
+	switch {
+	case value >= 1e6:
+		return strconv.FormatFloat(value/1e6, 'f', -1, 64) + "M"
+	case value >= 1e3:
+		return strconv.FormatFloat(value/1e3, 'f', -1, 64) + "k"
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
+
+func drawText(img *image.RGBA, text string, x, y int, c color.Color) {
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
+
+func textWidth(text string) int {
+	return font.MeasureString(basicfont.Face7x13, text).Round()
+}
+
+func drawHorizontalLine(img *image.RGBA, x0, x1, y int, c color.Color) {
+	for x := x0; x < x1; x++ {
+		img.Set(x, y, c)
+	}
+}
+
+// drawLine draws a line between two points using Bresenham's algorithm.
+func drawLine(img *image.RGBA, from, to image.Point, c color.Color) {
+	dx := abs(to.X - from.X)
+	dy := -abs(to.Y - from.Y)
+
+	sx, sy := 1, 1
+	if from.X > to.X {
+		sx = -1
+	}
+
+	if from.Y > to.Y {
+		sy = -1
+	}
+
+	errValue := dx + dy
+	x, y := from.X, from.Y
+
+	for {
+		img.Set(x, y, c)
+
+		if x == to.X && y == to.Y {
+			return
+		}
+
+		e2 := 2 * errValue
+		if e2 >= dy {
+			errValue += dy
+			x += sx
+		}
+
+		if e2 <= dx {
+			errValue += dx
+			y += sy
+		}
+	}
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
@@ -1,152 +1,516 @@
-// Package config handles application configuration loading from environment variables.
+// Package config handles application configuration loading from environment variables,
+// a .env file and an optional YAML, TOML or JSON configuration file.
 package config
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/presence"
 )
 
+// minPresenceInterval is the shortest time between two statuses, which keeps presence
+// updates well within the gateway rate limit.
+const minPresenceInterval = 15 * time.Second
+
+// MaxMentionsLimit is the highest accepted limit of user mentions in one message.
+const MaxMentionsLimit = 50
+
 // Config holds the application configuration settings.
+//
+// The env tag names the variable each setting is loaded from. Settings tagged
+// reload:"restart" are only read at startup, so a reload cannot change them.
+// Secret settings can also be read from the file named by the variable with a _FILE suffix.
 type Config struct {
-	DiscordToken    string
-	CommandPrefix   string
-	LogLevel        string
-	JSONLogging     bool
-	BotName         string
-	ShutdownTimeout time.Duration
-	RequestTimeout  time.Duration
-	MaxRetries      int
-	DebugMode       bool
-}
-
-// Load loads configuration from environment variables.
+	DiscordToken    Secret        `env:"DISCORD_TOKEN" reload:"restart"`
+	CommandPrefix   string        `env:"COMMAND_PREFIX"`
+	LogLevel        string        `env:"LOG_LEVEL"`
+	JSONLogging     bool          `env:"JSON_LOGGING" reload:"restart"`
+	BotName         string        `env:"BOT_NAME"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT"`
+	RequestTimeout  time.Duration `env:"HTTP_REQUEST_TIMEOUT"`
+	MaxRetries      int           `env:"HTTP_MAX_RETRIES"`
+	DebugMode       bool          `env:"DEBUG"`
+	MetricsAddr     string        `env:"METRICS_ADDR" reload:"restart"`
+	MetricsPerGuild bool          `env:"METRICS_PER_GUILD"`
+
+	MetricsPersist          bool          `env:"METRICS_PERSIST" reload:"restart"`
+	MetricsStateFile        string        `env:"METRICS_STATE_FILE" reload:"restart"`
+	MetricsSnapshotInterval time.Duration `env:"METRICS_SNAPSHOT_INTERVAL" reload:"restart"`
+
+	StatsDAddr      string        `env:"STATSD_ADDR" reload:"restart"`
+	StatsDPrefix    string        `env:"STATSD_PREFIX" reload:"restart"`
+	StatsDInterval  time.Duration `env:"STATSD_INTERVAL" reload:"restart"`
+	StatsDTags      []string      `env:"STATSD_TAGS" reload:"restart"`
+	StatsDDogStatsD bool          `env:"STATSD_DOGSTATSD" reload:"restart"`
+
+	TracingEnabled     bool    `env:"TRACING_ENABLED" reload:"restart"`
+	TracingEndpoint    string  `env:"TRACING_ENDPOINT" reload:"restart"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" reload:"restart"`
+
+	OwnerIDs []string `env:"OWNER_IDS"`
+
+	AlertsEnabled           bool          `env:"ALERT_ENABLED" reload:"restart"`
+	AlertChannelID          string        `env:"ALERT_CHANNEL_ID"`
+	AlertInterval           time.Duration `env:"ALERT_INTERVAL" reload:"restart"`
+	AlertCooldown           time.Duration `env:"ALERT_COOLDOWN" reload:"restart"`
+	AlertWindow             time.Duration `env:"ALERT_WINDOW" reload:"restart"`
+	AlertFailureRate        float64       `env:"ALERT_FAILURE_RATE" reload:"restart"`
+	AlertMinCommands        int           `env:"ALERT_MIN_COMMANDS" reload:"restart"`
+	AlertRateLimitThreshold int           `env:"ALERT_RATE_LIMIT_THRESHOLD" reload:"restart"`
+	AlertGatewayTimeout     time.Duration `env:"ALERT_GATEWAY_TIMEOUT" reload:"restart"`
+
+	PresenceStatuses []string      `env:"PRESENCE_STATUSES"`
+	PresenceInterval time.Duration `env:"PRESENCE_INTERVAL"`
+
+	MaintenanceMode    bool   `env:"MAINTENANCE_MODE"`
+	MaintenanceMessage string `env:"MAINTENANCE_MESSAGE"`
+	MaintenanceETA     string `env:"MAINTENANCE_ETA"`
+
+	ModerationFilterInvites bool `env:"MODERATION_FILTER_INVITES"`
+	ModerationMaxMentions   int  `env:"MODERATION_MAX_MENTIONS"`
+
+	AbuseStrikeLimit     int           `env:"ABUSE_STRIKE_LIMIT"`
+	AbuseStrikeWindow    time.Duration `env:"ABUSE_STRIKE_WINDOW"`
+	AbuseBlockDuration   time.Duration `env:"ABUSE_BLOCK_DURATION"`
+	AbuseCommandCooldown time.Duration `env:"ABUSE_COMMAND_COOLDOWN"`
+
+	StorageBackend   string `env:"STORAGE_BACKEND" reload:"restart"`
+	StoragePath      string `env:"STORAGE_PATH" reload:"restart"`
+	StorageBackupDir string `env:"STORAGE_BACKUP_DIR" reload:"restart"`
+
+	// problems found while loading, reported by Validate.
+	problems []Problem
+	// sources records the layer each setting was loaded from.
+	sources map[string]Source
+}
+
+// Load loads configuration from the environment and the .env file in the working directory,
+// and from the file named by CONFIG_FILE when it is set.
 func Load() (*Config, error) {
+	return LoadWithOptions(Options{})
+}
+
+// LoadWithOptions loads configuration from the layers selected by options.
+// Values are taken from, in order of precedence: the process environment, the .env file,
+// the selected profile of the configuration file, the configuration file, and the defaults.
+func LoadWithOptions(options Options) (*Config, error) {
+	l, err := loadLayers(options)
+	if err != nil {
+		return nil, errors.NewConfigError("failed to load configuration", err)
+	}
+
 	cfg := &Config{
 		CommandPrefix:   "!",    // default prefix.
 		LogLevel:        "info", // default log level.
 		JSONLogging:     false,  // default to text logging.
-		BotName:         getEnv("BOT_NAME", "discord-bot"),
+		BotName:         l.string("BOT_NAME", "discord-bot"),
 		ShutdownTimeout: 30 * time.Second, // default shutdown timeout.
 		RequestTimeout:  30 * time.Second, // default request timeout.
 		MaxRetries:      3,                // default max retries.
 		DebugMode:       false,            // default debug mode.
-	}
 
-	// Discord token is required.
-	cfg.DiscordToken = os.Getenv("DISCORD_TOKEN")
-	if cfg.DiscordToken == "" {
-		return nil, fmt.Errorf("DISCORD_TOKEN environment variable is required")
+		MetricsPersist:          true,                                                // default to persisting metrics.
+		MetricsStateFile:        l.string("METRICS_STATE_FILE", "data/metrics.json"), // default metrics state file.
+		MetricsSnapshotInterval: time.Minute,                                         // default snapshot interval.
+
+		StatsDPrefix:    l.string("STATSD_PREFIX", "discogo"), // default StatsD metric prefix.
+		StatsDInterval:  10 * time.Second,                     // default StatsD flush interval.
+		StatsDDogStatsD: true,                                 // default to DogStatsD tags.
+
+		TracingEnabled:     false,                                                           // default to no tracing.
+		TracingEndpoint:    l.string("TRACING_ENDPOINT", "http://localhost:4318/v1/traces"), // default local OTLP/HTTP collector.
+		TracingSampleRatio: 1.0,                                                             // default to sampling every trace.
+
+		AlertsEnabled:           false,            // default to no alerting.
+		AlertInterval:           30 * time.Second, // default rule evaluation interval.
+		AlertCooldown:           15 * time.Minute, // default minimum time between repeated alerts.
+		AlertWindow:             5 * time.Minute,  // default evaluation window.
+		AlertFailureRate:        25,               // default failure rate threshold in percent.
+		AlertMinCommands:        10,               // default minimum commands before the failure rate counts.
+		AlertRateLimitThreshold: 5,                // default rate limit hits per window.
+		AlertGatewayTimeout:     time.Minute,      // default gateway downtime before alerting.
+
+		PresenceInterval: 2 * time.Minute, // default time between two statuses.
+
+		AbuseStrikeLimit:     10,               // default strikes before a temporary block.
+		AbuseStrikeWindow:    10 * time.Minute, // default window in which strikes add up.
+		AbuseBlockDuration:   time.Hour,        // default length of automatic blocks.
+		AbuseCommandCooldown: 2 * time.Second,  // default minimum time between two commands of a user.
+
+		StorageBackend:   l.string("STORAGE_BACKEND", "bolt"),            // default embedded database.
+		StoragePath:      l.string("STORAGE_PATH", "data/discogo.db"),    // default database file.
+		StorageBackupDir: l.string("STORAGE_BACKUP_DIR", "data/backups"), // default migration backup directory.
 	}
 
+	// Discord token is required; Validate reports it when missing.
+	cfg.DiscordToken = l.secret("DISCORD_TOKEN")
+
 	// Optional configurations.
-	if prefix := os.Getenv("COMMAND_PREFIX"); prefix != "" {
+	if prefix := l.get("COMMAND_PREFIX"); prefix != "" {
 		cfg.CommandPrefix = prefix
 	}
 
-	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
+	if logLevel := l.get("LOG_LEVEL"); logLevel != "" {
 		cfg.LogLevel = strings.ToLower(logLevel)
 	}
 
 	// Parse timeout configurations.
-	if timeout := os.Getenv("SHUTDOWN_TIMEOUT"); timeout != "" {
-		if parsed, err := time.ParseDuration(timeout); err == nil {
-			cfg.ShutdownTimeout = parsed
-		} else {
-			log.Printf("Warning: invalid SHUTDOWN_TIMEOUT format '%s', using default", timeout)
-		}
-	}
-
-	if timeout := os.Getenv("REQUEST_TIMEOUT"); timeout != "" {
-		if parsed, err := time.ParseDuration(timeout); err == nil {
-			cfg.RequestTimeout = parsed
-		} else {
-			log.Printf("Warning: invalid REQUEST_TIMEOUT format '%s', using default", timeout)
-		}
-	}
+	cfg.ShutdownTimeout = l.duration("SHUTDOWN_TIMEOUT", cfg.ShutdownTimeout)
+	cfg.RequestTimeout = l.duration("HTTP_REQUEST_TIMEOUT", cfg.RequestTimeout)
 
 	// Parse retry configuration.
-	cfg.MaxRetries = GetInt("MAX_RETRIES", cfg.MaxRetries)
+	cfg.MaxRetries = l.int("HTTP_MAX_RETRIES", cfg.MaxRetries)
 
 	// Parse debug mode.
-	cfg.DebugMode = GetBool("DEBUG", cfg.DebugMode)
+	cfg.DebugMode = l.bool("DEBUG", cfg.DebugMode)
 
 	// Parse JSON logging.
-	cfg.JSONLogging = GetBool("JSON_LOGGING", cfg.JSONLogging)
+	cfg.JSONLogging = l.bool("JSON_LOGGING", cfg.JSONLogging)
+
+	// Metrics endpoint is disabled unless an address is configured.
+	cfg.MetricsAddr = l.get("METRICS_ADDR")
+
+	// Parse per-guild command metrics.
+	cfg.MetricsPerGuild = l.bool("METRICS_PER_GUILD", cfg.MetricsPerGuild)
+
+	// Parse metrics persistence.
+	cfg.MetricsPersist = l.bool("METRICS_PERSIST", cfg.MetricsPersist)
+	cfg.MetricsSnapshotInterval = l.duration("METRICS_SNAPSHOT_INTERVAL", cfg.MetricsSnapshotInterval)
+
+	// Parse StatsD push exporter configuration; disabled unless an address is configured.
+	cfg.StatsDAddr = l.get("STATSD_ADDR")
+	cfg.StatsDInterval = l.duration("STATSD_INTERVAL", cfg.StatsDInterval)
+	cfg.StatsDTags = l.list("STATSD_TAGS")
+	cfg.StatsDDogStatsD = l.bool("STATSD_DOGSTATSD", cfg.StatsDDogStatsD)
+
+	// Parse tracing configuration.
+	cfg.TracingEnabled = l.bool("TRACING_ENABLED", cfg.TracingEnabled)
+	cfg.TracingSampleRatio = l.float("TRACING_SAMPLE_RATIO", cfg.TracingSampleRatio)
+
+	// Parse bot owners.
+	cfg.OwnerIDs = l.list("OWNER_IDS")
+
+	// Parse alerting configuration.
+	cfg.AlertsEnabled = l.bool("ALERT_ENABLED", cfg.AlertsEnabled)
+	cfg.AlertChannelID = l.get("ALERT_CHANNEL_ID")
+	cfg.AlertInterval = l.duration("ALERT_INTERVAL", cfg.AlertInterval)
+	cfg.AlertCooldown = l.duration("ALERT_COOLDOWN", cfg.AlertCooldown)
+	cfg.AlertWindow = l.duration("ALERT_WINDOW", cfg.AlertWindow)
+	cfg.AlertFailureRate = l.float("ALERT_FAILURE_RATE", cfg.AlertFailureRate)
+	cfg.AlertMinCommands = l.int("ALERT_MIN_COMMANDS", cfg.AlertMinCommands)
+	cfg.AlertRateLimitThreshold = l.int("ALERT_RATE_LIMIT_THRESHOLD", cfg.AlertRateLimitThreshold)
+	cfg.AlertGatewayTimeout = l.duration("ALERT_GATEWAY_TIMEOUT", cfg.AlertGatewayTimeout)
+
+	// Parse presence configuration.
+	cfg.PresenceStatuses = l.list("PRESENCE_STATUSES")
+	cfg.PresenceInterval = l.duration("PRESENCE_INTERVAL", cfg.PresenceInterval)
+
+	// Parse maintenance mode configuration.
+	cfg.MaintenanceMode = l.bool("MAINTENANCE_MODE", cfg.MaintenanceMode)
+	cfg.MaintenanceMessage = l.get("MAINTENANCE_MESSAGE")
+	cfg.MaintenanceETA = l.get("MAINTENANCE_ETA")
+
+	// Parse the moderation defaults of guilds that have not changed them.
+	cfg.ModerationFilterInvites = l.bool("MODERATION_FILTER_INVITES", cfg.ModerationFilterInvites)
+	cfg.ModerationMaxMentions = l.int("MODERATION_MAX_MENTIONS", cfg.ModerationMaxMentions)
+
+	// Parse abuse detection configuration.
+	cfg.AbuseStrikeLimit = l.int("ABUSE_STRIKE_LIMIT", cfg.AbuseStrikeLimit)
+	cfg.AbuseStrikeWindow = l.duration("ABUSE_STRIKE_WINDOW", cfg.AbuseStrikeWindow)
+	cfg.AbuseBlockDuration = l.duration("ABUSE_BLOCK_DURATION", cfg.AbuseBlockDuration)
+	cfg.AbuseCommandCooldown = l.duration("ABUSE_COMMAND_COOLDOWN", cfg.AbuseCommandCooldown)
+
+	// Unparsable values and unknown settings are reported by Validate, together with
+	// every other problem.
+	cfg.problems = append(l.problems, l.unknownKeys()...)
+	cfg.sources = l.sources
 
 	return cfg, nil
 }
 
-// Validate validates the configuration.
+// Validate validates the configuration. It reports every problem at once, including
+// the values Load could not parse and unknown settings, as a single config error whose
+// individual problems are available through Problems.
 func (c *Config) Validate() error {
-	if c.DiscordToken == "" {
-		return fmt.Errorf("discord token is required")
+	return c.validate(true)
+}
+
+// ValidateOffline validates the configuration like Validate, but without the settings that
+// are only needed to connect to Discord, such as DISCORD_TOKEN. Commands that only work on
+// the storage, such as migrations, use it.
+func (c *Config) ValidateOffline() error {
+	return c.validate(false)
+}
+
+// validate reports every problem of the configuration, including the settings needed to
+// connect to Discord when online is set.
+func (c *Config) validate(online bool) error {
+	problems := append([]Problem(nil), c.problems...)
+
+	check := func(ok bool, key, message string, args ...any) {
+		if !ok {
+			problems = append(problems, Problem{Key: key, Message: fmt.Sprintf(message, args...)})
+		}
 	}
 
-	if c.CommandPrefix == "" {
-		return fmt.Errorf("command prefix cannot be empty")
+	if online {
+		check(c.DiscordToken.IsSet(), "DISCORD_TOKEN",
+			"is required (set it, or DISCORD_TOKEN_FILE, in the environment, .env or the config file)")
 	}
 
+	check(c.CommandPrefix != "", "COMMAND_PREFIX", "cannot be empty")
+
 	validLogLevels := []string{"debug", "info", "warn", "error"}
-	if !contains(validLogLevels, c.LogLevel) {
-		return fmt.Errorf("invalid log level: %s (valid: %s)", c.LogLevel, strings.Join(validLogLevels, ", "))
+	check(contains(validLogLevels, c.LogLevel), "LOG_LEVEL", "invalid log level %q (valid: %s)",
+		c.LogLevel, strings.Join(validLogLevels, ", "))
+
+	check(c.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT", "must be positive")
+	check(c.RequestTimeout > 0, "HTTP_REQUEST_TIMEOUT", "must be positive")
+	check(c.MaxRetries >= 0, "HTTP_MAX_RETRIES", "cannot be negative")
+
+	if c.MetricsPersist {
+		check(c.MetricsSnapshotInterval > 0, "METRICS_SNAPSHOT_INTERVAL", "must be positive")
+		check(c.MetricsStateFile != "", "METRICS_STATE_FILE", "is required when metrics persistence is enabled")
+	}
+
+	if c.StatsDAddr != "" {
+		check(c.StatsDInterval > 0, "STATSD_INTERVAL", "must be positive")
+	}
+
+	for _, tag := range c.StatsDTags {
+		check(strings.Contains(tag, ":"), "STATSD_TAGS", "invalid tag %q (expected key:value)", tag)
+	}
+
+	if c.TracingEnabled {
+		check(c.TracingEndpoint != "", "TRACING_ENDPOINT", "is required when tracing is enabled")
+	}
+
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "TRACING_SAMPLE_RATIO",
+		"must be between 0 and 1, got %v", c.TracingSampleRatio)
+
+	for _, id := range c.OwnerIDs {
+		check(IsSnowflake(id), "OWNER_IDS", "invalid user ID %q (expected a Discord ID)", id)
+	}
+
+	if c.AlertsEnabled {
+		check(c.AlertChannelID != "" || len(c.OwnerIDs) > 0, "ALERT_CHANNEL_ID",
+			"alerting needs an alert channel or at least one owner in OWNER_IDS to notify")
+
+		if c.AlertChannelID != "" {
+			check(IsSnowflake(c.AlertChannelID), "ALERT_CHANNEL_ID", "invalid channel ID %q (expected a Discord ID)",
+				c.AlertChannelID)
+		}
+
+		check(c.AlertInterval > 0, "ALERT_INTERVAL", "must be positive")
+		check(c.AlertWindow > 0, "ALERT_WINDOW", "must be positive")
+		check(c.AlertCooldown >= 0, "ALERT_COOLDOWN", "cannot be negative")
+		check(c.AlertFailureRate >= 0 && c.AlertFailureRate <= 100, "ALERT_FAILURE_RATE",
+			"must be between 0 and 100, got %v", c.AlertFailureRate)
+	}
+
+	for _, status := range c.PresenceStatuses {
+		_, err := presence.Parse(status)
+		check(err == nil, "PRESENCE_STATUSES", "%v", err)
 	}
 
-	if c.ShutdownTimeout <= 0 {
-		return fmt.Errorf("shutdown timeout must be positive")
+	check(c.PresenceInterval >= minPresenceInterval, "PRESENCE_INTERVAL", "must be at least %s, got %s",
+		minPresenceInterval, c.PresenceInterval)
+
+	if c.MaintenanceETA != "" {
+		_, err := time.Parse(time.RFC3339, c.MaintenanceETA)
+		check(err == nil, "MAINTENANCE_ETA", "invalid time %q (expected RFC 3339, such as 2024-05-01T18:00:00Z)",
+			c.MaintenanceETA)
 	}
 
-	if c.RequestTimeout <= 0 {
-		return fmt.Errorf("request timeout must be positive")
+	check(c.ModerationMaxMentions >= 0 && c.ModerationMaxMentions <= MaxMentionsLimit, "MODERATION_MAX_MENTIONS",
+		"must be between 0 and %d, got %d", MaxMentionsLimit, c.ModerationMaxMentions)
+
+	check(c.AbuseStrikeLimit >= 0, "ABUSE_STRIKE_LIMIT", "cannot be negative")
+	check(c.AbuseCommandCooldown >= 0, "ABUSE_COMMAND_COOLDOWN", "cannot be negative")
+
+	if c.AbuseStrikeLimit > 0 {
+		check(c.AbuseStrikeWindow > 0, "ABUSE_STRIKE_WINDOW", "must be positive")
+		check(c.AbuseBlockDuration > 0, "ABUSE_BLOCK_DURATION", "must be positive")
 	}
 
-	if c.MaxRetries < 0 {
-		return fmt.Errorf("max retries cannot be negative")
+	validBackends := []string{"bolt", "memory"}
+	check(contains(validBackends, c.StorageBackend), "STORAGE_BACKEND", "invalid storage backend %q (valid: %s)",
+		c.StorageBackend, strings.Join(validBackends, ", "))
+
+	if c.StorageBackend == "bolt" {
+		check(c.StoragePath != "", "STORAGE_PATH", "is required with the bolt storage backend")
 	}
 
-	return nil
+	return problemsError(problems)
+}
+
+// Source returns the layer the setting with the given key was loaded from.
+// Settings no layer set, and configurations not created by Load, report SourceDefault.
+func (c *Config) Source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+
+	return SourceDefault
+}
+
+// IsOwner reports whether the user is one of the configured bot owners.
+func (c *Config) IsOwner(userID string) bool {
+	return contains(c.OwnerIDs, userID)
 }
 
-// GetBool returns a boolean environment variable with a default value.
+// LookupBool returns a boolean environment variable, or defaultValue when it is not set.
+// An invalid value returns defaultValue and a Problem describing the value.
+func LookupBool(key string, defaultValue bool) (bool, error) {
+	l := newLayers()
+	value := l.bool(key, defaultValue)
+
+	return value, lookupError(l)
+}
+
+// LookupInt returns an integer environment variable, or defaultValue when it is not set.
+// An invalid value returns defaultValue and a Problem describing the value.
+func LookupInt(key string, defaultValue int) (int, error) {
+	l := newLayers()
+	value := l.int(key, defaultValue)
+
+	return value, lookupError(l)
+}
+
+// GetBool returns a boolean environment variable with a default value. An invalid value
+// is logged and replaced by the default.
+//
+// Deprecated: Use LookupBool, which returns the problem with an invalid value, or read the
+// setting from the Config returned by Load.
 func GetBool(key string, defaultValue bool) bool {
-	value := os.Getenv(key)
+	value, err := LookupBool(key, defaultValue)
+	if err != nil {
+		slog.Warn("Ignoring invalid environment variable", "error", err)
+	}
+
+	return value
+}
+
+// GetInt returns an integer environment variable with a default value. An invalid value
+// is logged and replaced by the default.
+//
+// Deprecated: Use LookupInt, which returns the problem with an invalid value, or read the
+// setting from the Config returned by Load.
+func GetInt(key string, defaultValue int) int {
+	value, err := LookupInt(key, defaultValue)
+	if err != nil {
+		slog.Warn("Ignoring invalid environment variable", "error", err)
+	}
+
+	return value
+}
+
+// lookupError returns the problem found by a single lookup, if any.
+func lookupError(l *layers) error {
+	if len(l.problems) == 0 {
+		return nil
+	}
+
+	return l.problems[0]
+}
+
+// string returns the value of key with a default value.
+func (l *layers) string(key, defaultValue string) string {
+	if value := l.get(key); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
+
+// bool returns the boolean value of key with a default value.
+// Invalid values are recorded as problems and replaced by the default; the same applies
+// to the other typed getters.
+func (l *layers) bool(key string, defaultValue bool) bool {
+	value := l.get(key)
 	if value == "" {
 		return defaultValue
 	}
 
 	boolVal, err := strconv.ParseBool(value)
 	if err != nil {
+		l.invalid(key, value, "expected a boolean (true or false)")
 		return defaultValue
 	}
 
 	return boolVal
 }
 
-// GetInt returns an integer environment variable with a default value.
-func GetInt(key string, defaultValue int) int {
-	value := os.Getenv(key)
+// int returns the integer value of key with a default value.
+func (l *layers) int(key string, defaultValue int) int {
+	value := l.get(key)
 	if value == "" {
 		return defaultValue
 	}
 
 	intVal, err := strconv.Atoi(value)
 	if err != nil {
+		l.invalid(key, value, "expected a whole number such as 3")
 		return defaultValue
 	}
 
 	return intVal
 }
 
-// getEnv returns an environment variable with a default value.
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// float returns the floating-point value of key with a default value.
+func (l *layers) float(key string, defaultValue float64) float64 {
+	value := l.get(key)
+	if value == "" {
+		return defaultValue
 	}
 
-	return defaultValue
+	floatVal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		l.invalid(key, value, "expected a number such as 0.5")
+		return defaultValue
+	}
+
+	return floatVal
+}
+
+// duration returns the duration value of key with a default value.
+func (l *layers) duration(key string, defaultValue time.Duration) time.Duration {
+	value := l.get(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		l.invalid(key, value, "expected a duration such as 30s, 5m or 1h")
+		return defaultValue
+	}
+
+	return duration
+}
+
+// invalid records a value that could not be parsed. The default is used in its place
+// until Validate reports the problem.
+func (l *layers) invalid(key, value, message string) {
+	l.problems = append(l.problems, Problem{Key: key, Value: value, Message: message})
+}
+
+// list returns the comma-separated value of key as a list, skipping empty items.
+func (l *layers) list(key string) []string {
+	var items []string
+
+	for _, item := range strings.Split(l.get(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
 }
 
 // contains checks if a slice contains a string.
@@ -159,3 +523,18 @@ func contains(slice []string, item string) bool {
 
 	return false
 }
+
+// IsSnowflake reports whether id looks like a Discord ID.
+func IsSnowflake(id string) bool {
+	if len(id) < 15 || len(id) > 21 {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
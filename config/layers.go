@@ -0,0 +1,365 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Source identifies the layer a configuration value came from.
+type Source string
+
+const (
+	// SourceDefault means no layer set the value and the built-in default is used.
+	SourceDefault Source = "default"
+	// SourceFile means the value came from the configuration file.
+	SourceFile Source = "file"
+	// SourceDotEnv means the value came from the .env file.
+	SourceDotEnv Source = "dotenv"
+	// SourceEnv means the value came from the process environment.
+	SourceEnv Source = "env"
+	// SourceSecretFile means the value was read from the file named by the _FILE variable of a secret.
+	SourceSecretFile Source = "secret_file"
+)
+
+// DefaultEnvFile is the .env file read when Options.EnvFile is empty.
+const DefaultEnvFile = ".env"
+
+// profilesKey is the top-level configuration file section holding the profiles.
+const profilesKey = "profiles"
+
+// Options selects the configuration layers to load.
+type Options struct {
+	// ConfigFile is a YAML, TOML or JSON configuration file. When empty, CONFIG_FILE is used;
+	// when that is empty too, no file is read.
+	ConfigFile string
+	// Profile selects a section under "profiles" in the configuration file that overrides
+	// the base values. When empty, CONFIG_PROFILE is used.
+	Profile string
+	// EnvFile is the .env file to read; DefaultEnvFile when empty. A missing file is not an error.
+	EnvFile string
+}
+
+// layers resolves configuration keys from the environment, the .env file and the
+// configuration file, in that order of precedence.
+type layers struct {
+	dotenv map[string]string
+	file   map[string]string
+
+	// seen holds every key that was looked up, which makes the remaining keys unknown.
+	seen map[string]bool
+	// sources records the layer each looked up key was taken from.
+	sources map[string]Source
+	// problems collects the values that could not be parsed.
+	problems []Problem
+}
+
+func newLayers() *layers {
+	return &layers{seen: make(map[string]bool), sources: make(map[string]Source)}
+}
+
+// lookup returns the value of key from the highest-precedence layer that sets it,
+// and records that layer as the source of key.
+func (l *layers) lookup(key string) (string, Source) {
+	value, source := l.resolve(key)
+
+	l.seen[key] = true
+	l.sources[key] = source
+
+	for _, former := range formerNames(key) {
+		l.seen[former] = true
+	}
+
+	return value, source
+}
+
+// resolve finds the layer that sets key. A variable that is set in the environment or the
+// .env file wins even when it is empty, so it can clear a value of the configuration file;
+// the empty value then stands for the built-in default. Within a layer, the current name of
+// a renamed setting takes precedence over its former names.
+func (l *layers) resolve(key string) (string, Source) {
+	names := append([]string{key}, formerNames(key)...)
+
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, SourceEnv
+		}
+	}
+
+	for _, name := range names {
+		if value, ok := l.dotenv[name]; ok {
+			return value, SourceDotEnv
+		}
+	}
+
+	for _, name := range names {
+		if value, ok := l.file[name]; ok && value != "" {
+			return value, SourceFile
+		}
+	}
+
+	return "", SourceDefault
+}
+
+// renamedKeys maps the former names of renamed settings to their current names. The former
+// names are still read, so existing environments and files keep working.
+var renamedKeys = map[string]string{
+	"ALERTS_ENABLED":  "ALERT_ENABLED",
+	"REQUEST_TIMEOUT": "HTTP_REQUEST_TIMEOUT",
+	"MAX_RETRIES":     "HTTP_MAX_RETRIES",
+}
+
+// formerNames returns the former names of a setting.
+func formerNames(key string) []string {
+	var names []string
+
+	for former, current := range renamedKeys {
+		if current == key {
+			names = append(names, former)
+		}
+	}
+
+	return names
+}
+
+// get returns the value of key, or an empty string when no layer sets it.
+func (l *layers) get(key string) string {
+	value, _ := l.lookup(key)
+	return value
+}
+
+// loadLayers reads the .env and configuration file layers selected by options.
+func loadLayers(options Options) (*layers, error) {
+	envFile := options.EnvFile
+	if envFile == "" {
+		envFile = DefaultEnvFile
+	}
+
+	dotenv, err := readDotEnv(envFile)
+	if err != nil {
+		return nil, err
+	}
+
+	l := newLayers()
+	l.dotenv = dotenv
+
+	// Both keys are always looked up, so they never count as unknown.
+	configFile := l.get("CONFIG_FILE")
+	if options.ConfigFile != "" {
+		configFile = options.ConfigFile
+	}
+
+	profile := l.get("CONFIG_PROFILE")
+	if options.Profile != "" {
+		profile = options.Profile
+	}
+
+	if configFile == "" {
+		if profile != "" {
+			return nil, fmt.Errorf("profile %q selected without a config file", profile)
+		}
+
+		return l, nil
+	}
+
+	l.file, err = readConfigFile(configFile, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// readDotEnv parses a .env file of KEY=value lines. Blank lines and lines starting
+// with # are ignored, and matching surrounding quotes are removed from values.
+func readDotEnv(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !found {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// readConfigFile decodes a configuration file, applies the selected profile and
+// flattens the result into environment variable keys.
+//
+// Nested sections are joined with underscores and upper-cased, so
+//
+//	metrics:
+//	  addr: ":9090"
+//
+// sets METRICS_ADDR. Lists are joined with commas.
+func readConfigFile(path, profile string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var tree map[string]any
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	case ".json":
+		// Numbers are kept as written, so Discord IDs do not lose precision as float64.
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&tree)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q (expected .yaml, .yml, .toml or .json)", ext)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	profiles, _ := tree[profilesKey].(map[string]any)
+	delete(tree, profilesKey)
+
+	values := make(map[string]string)
+	if err := flatten("", tree, values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if profile == "" {
+		return values, nil
+	}
+
+	overrides, ok := profiles[profile].(map[string]any)
+	if !ok {
+		available := "none"
+		if len(profiles) > 0 {
+			available = strings.Join(sortedKeys(profiles), ", ")
+		}
+
+		return nil, fmt.Errorf("profile %q not found in config file %s (available: %s)", profile, path, available)
+	}
+
+	if err := flatten("", overrides, values); err != nil {
+		return nil, fmt.Errorf("invalid profile %q in config file %s: %w", profile, path, err)
+	}
+
+	return values, nil
+}
+
+// flatten writes the scalar values of tree into values, keyed by their upper-cased path.
+func flatten(prefix string, tree map[string]any, values map[string]string) error {
+	for name, node := range tree {
+		key := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch value := node.(type) {
+		case map[string]any:
+			if err := flatten(key, value, values); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, 0, len(value))
+
+			for _, item := range value {
+				formatted, err := formatScalar(item)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+
+				items = append(items, formatted)
+			}
+
+			values[key] = strings.Join(items, ",")
+		default:
+			formatted, err := formatScalar(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+
+			values[key] = formatted
+		}
+	}
+
+	return nil
+}
+
+// formatScalar formats a decoded scalar the way it would be written in an environment variable.
+func formatScalar(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v of type %T", value, value)
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// unsetenv removes environment variables for the duration of a test.
+func unsetenv(t *testing.T, keys ...string) {
+	t.Helper()
+
+	for _, key := range keys {
+		t.Setenv(key, "")
+		_ = os.Unsetenv(key)
+	}
+}
+
+// writeFile writes a file in the test's temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	const file = `
+command_prefix: "file"
+profiles:
+  staging:
+    command_prefix: "profile"
+`
+
+	tests := []struct {
+		name       string
+		file       bool
+		profile    string
+		dotenv     string
+		env        string
+		wantPrefix string
+		wantSource Source
+	}{
+		{name: "default", wantPrefix: "!", wantSource: SourceDefault},
+		{name: "file over default", file: true, wantPrefix: "file", wantSource: SourceFile},
+		{name: "profile over file", file: true, profile: "staging", wantPrefix: "profile", wantSource: SourceFile},
+		{name: "dotenv over profile", file: true, profile: "staging", dotenv: "dotenv", wantPrefix: "dotenv",
+			wantSource: SourceDotEnv},
+		{name: "env over dotenv", file: true, profile: "staging", dotenv: "dotenv", env: "env", wantPrefix: "env",
+			wantSource: SourceEnv},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetenv(t, "COMMAND_PREFIX", "CONFIG_FILE", "CONFIG_PROFILE")
+
+			options := Options{Profile: tt.profile, EnvFile: filepath.Join(t.TempDir(), "missing.env")}
+
+			if tt.file {
+				options.ConfigFile = writeFile(t, "config.yaml", file)
+			}
+
+			if tt.dotenv != "" {
+				options.EnvFile = writeFile(t, ".env", "COMMAND_PREFIX="+tt.dotenv+"\n")
+			}
+
+			if tt.env != "" {
+				t.Setenv("COMMAND_PREFIX", tt.env)
+			}
+
+			cfg, err := LoadWithOptions(options)
+			if err != nil {
+				t.Fatalf("LoadWithOptions() failed: %v", err)
+			}
+
+			if cfg.CommandPrefix != tt.wantPrefix {
+				t.Errorf("CommandPrefix = %q, want %q", cfg.CommandPrefix, tt.wantPrefix)
+			}
+
+			if source := cfg.Source("COMMAND_PREFIX"); source != tt.wantSource {
+				t.Errorf("Source(COMMAND_PREFIX) = %q, want %q", source, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestLoadEmptyEnvClearsFile(t *testing.T) {
+	unsetenv(t, "CONFIG_FILE", "CONFIG_PROFILE")
+	t.Setenv("COMMAND_PREFIX", "")
+
+	cfg, err := LoadWithOptions(Options{
+		ConfigFile: writeFile(t, "config.yaml", "command_prefix: \"?\"\n"),
+		EnvFile:    filepath.Join(t.TempDir(), "missing.env"),
+	})
+	if err != nil {
+		t.Fatalf("LoadWithOptions() failed: %v", err)
+	}
+
+	if cfg.CommandPrefix != "!" {
+		t.Errorf("CommandPrefix = %q, want the default", cfg.CommandPrefix)
+	}
+}
+
+func TestLoadFileFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "config.yaml",
+			content: `
+metrics:
+  addr: ":9090"
+owner_ids: [123456789012345678]
+http:
+  request_timeout: 5s
+`,
+		},
+		{
+			name: "config.toml",
+			content: `
+owner_ids = ["123456789012345678"]
+
+[metrics]
+addr = ":9090"
+
+[http]
+request_timeout = "5s"
+`,
+		},
+		{
+			name:    "config.json",
+			content: `{"metrics": {"addr": ":9090"}, "owner_ids": [123456789012345678], "http": {"request_timeout": "5s"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetenv(t, "CONFIG_FILE", "CONFIG_PROFILE", "METRICS_ADDR", "OWNER_IDS", "HTTP_REQUEST_TIMEOUT",
+				"REQUEST_TIMEOUT")
+
+			cfg, err := LoadWithOptions(Options{
+				ConfigFile: writeFile(t, tt.name, tt.content),
+				EnvFile:    filepath.Join(t.TempDir(), "missing.env"),
+			})
+			if err != nil {
+				t.Fatalf("LoadWithOptions() failed: %v", err)
+			}
+
+			if cfg.MetricsAddr != ":9090" {
+				t.Errorf("MetricsAddr = %q, want %q", cfg.MetricsAddr, ":9090")
+			}
+
+			if !slices.Equal(cfg.OwnerIDs, []string{"123456789012345678"}) {
+				t.Errorf("OwnerIDs = %v, want the ID unchanged", cfg.OwnerIDs)
+			}
+
+			if cfg.RequestTimeout != 5*time.Second {
+				t.Errorf("RequestTimeout = %s, want 5s", cfg.RequestTimeout)
+			}
+		})
+	}
+}
+
+func TestLoadRenamedKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantTimeout time.Duration
+	}{
+		{name: "former name", env: map[string]string{"REQUEST_TIMEOUT": "5s"}, wantTimeout: 5 * time.Second},
+		{name: "current name", env: map[string]string{"HTTP_REQUEST_TIMEOUT": "7s"}, wantTimeout: 7 * time.Second},
+		{
+			name:        "current name over former name",
+			env:         map[string]string{"REQUEST_TIMEOUT": "5s", "HTTP_REQUEST_TIMEOUT": "7s"},
+			wantTimeout: 7 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetenv(t, "CONFIG_FILE", "CONFIG_PROFILE", "REQUEST_TIMEOUT", "HTTP_REQUEST_TIMEOUT")
+
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := LoadWithOptions(Options{EnvFile: filepath.Join(t.TempDir(), "missing.env")})
+			if err != nil {
+				t.Fatalf("LoadWithOptions() failed: %v", err)
+			}
+
+			if cfg.RequestTimeout != tt.wantTimeout {
+				t.Errorf("RequestTimeout = %s, want %s", cfg.RequestTimeout, tt.wantTimeout)
+			}
+
+			for _, problem := range Problems(cfg.ValidateOffline()) {
+				if problem.Key == "REQUEST_TIMEOUT" {
+					t.Errorf("former name reported as a problem: %v", problem)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateReportsFileProblems(t *testing.T) {
+	unsetenv(t, "CONFIG_FILE", "CONFIG_PROFILE", "HTTP_MAX_RETRIES", "DEBUG")
+
+	cfg, err := LoadWithOptions(Options{
+		ConfigFile: writeFile(t, "config.yaml", "http:\n  max_retries: many\ndebug: true\ncomand_prefix: \"?\"\n"),
+		EnvFile:    filepath.Join(t.TempDir(), "missing.env"),
+	})
+	if err != nil {
+		t.Fatalf("LoadWithOptions() failed: %v", err)
+	}
+
+	var keys []string
+	for _, problem := range Problems(cfg.ValidateOffline()) {
+		keys = append(keys, problem.Key)
+	}
+
+	for _, want := range []string{"HTTP_MAX_RETRIES", "COMAND_PREFIX"} {
+		if !slices.Contains(keys, want) {
+			t.Errorf("problems = %v, want one for %s", keys, want)
+		}
+	}
+
+	if slices.Contains(keys, "DEBUG") {
+		t.Errorf("problems = %v, want none for the valid DEBUG", keys)
+	}
+
+	if !cfg.DebugMode {
+		t.Error("DebugMode = false, want the file's value despite the other problems")
+	}
+}
+
+func TestLoadRejectsUnknownProfile(t *testing.T) {
+	unsetenv(t, "CONFIG_FILE", "CONFIG_PROFILE")
+
+	_, err := LoadWithOptions(Options{
+		ConfigFile: writeFile(t, "config.yaml", "profiles:\n  staging:\n    debug: true\n"),
+		Profile:    "production",
+		EnvFile:    filepath.Join(t.TempDir(), "missing.env"),
+	})
+	if err == nil {
+		t.Fatal("LoadWithOptions() accepted a profile the file does not define")
+	}
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dunamismax/discogo/errors"
+)
+
+// botPrefixes are the variable name prefixes owned by the bot. Environment and .env
+// variables with one of these prefixes that the bot does not know are reported as typos.
+// HTTP_ is left out, since HTTP_PROXY and similar variables belong to other programs.
+var botPrefixes = []string{
+	"ABUSE_", "ALERT_", "ALERTS_", "BOT_", "COMMAND_", "CONFIG_", "DISCORD_", "MAINTENANCE_", "METRICS_",
+	"MODERATION_", "OWNER_", "PRESENCE_", "STATSD_", "STORAGE_", "TRACING_",
+}
+
+// sourceDescriptions names the layers in problem reports.
+var sourceDescriptions = map[Source]string{
+	SourceEnv:    "the environment",
+	SourceDotEnv: "the .env file",
+	SourceFile:   "the config file",
+}
+
+// Problem describes one invalid configuration setting.
+type Problem struct {
+	// Key is the environment variable name of the setting.
+	Key string
+	// Value is the rejected value, if any.
+	Value string
+	// Message explains what is wrong or what was expected.
+	Message string
+}
+
+func (p Problem) Error() string {
+	if p.Value != "" {
+		return fmt.Sprintf("%s=%q: %s", p.Key, p.Value, p.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", p.Key, p.Message)
+}
+
+// Problems returns the individual problems of an error returned by Validate.
+func Problems(err error) []Problem {
+	var problems []Problem
+
+	var walk func(error)
+	walk = func(err error) {
+		if problem, ok := err.(Problem); ok {
+			problems = append(problems, problem)
+			return
+		}
+
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range wrapped.Unwrap() {
+				walk(e)
+			}
+		case interface{ Unwrap() error }:
+			if inner := wrapped.Unwrap(); inner != nil {
+				walk(inner)
+			}
+		}
+	}
+
+	if err != nil {
+		walk(err)
+	}
+
+	return problems
+}
+
+// problemsError aggregates problems into a single configuration error.
+func problemsError(problems []Problem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(problems))
+	for _, problem := range problems {
+		errs = append(errs, problem)
+	}
+
+	message := "1 configuration problem"
+	if len(problems) > 1 {
+		message = fmt.Sprintf("%d configuration problems", len(problems))
+	}
+
+	return errors.NewConfigError(message, stdErrors.Join(errs...))
+}
+
+// unknownKeys reports settings that were provided but never read. Every key of the
+// configuration file must be known; environment and .env variables are only checked
+// when they use one of the bot's prefixes, since they are shared with other programs.
+func (l *layers) unknownKeys() []Problem {
+	var problems []Problem
+
+	report := func(key string, source Source) {
+		if l.seen[key] {
+			return
+		}
+
+		message := "unknown setting in " + sourceDescriptions[source]
+		if suggestion := l.suggest(key); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %s?)", suggestion)
+		}
+
+		problems = append(problems, Problem{Key: key, Message: message})
+	}
+
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		if hasBotPrefix(key) {
+			report(key, SourceEnv)
+		}
+	}
+
+	for key := range l.dotenv {
+		if hasBotPrefix(key) {
+			report(key, SourceDotEnv)
+		}
+	}
+
+	for key := range l.file {
+		report(key, SourceFile)
+	}
+
+	// Environment variables are unordered; keep the report stable.
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Key < problems[j].Key })
+
+	return problems
+}
+
+// suggest returns the known key closest to key, if it is close enough to be a likely typo.
+func (l *layers) suggest(key string) string {
+	best, bestDistance := "", 3
+
+	for known := range l.seen {
+		if distance := editDistance(key, known); distance < bestDistance {
+			best, bestDistance = known, distance
+		}
+	}
+
+	return best
+}
+
+func hasBotPrefix(key string) bool {
+	for _, prefix := range botPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
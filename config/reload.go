@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Field describes one setting of Config.
+type Field struct {
+	// Key is the environment variable the setting is loaded from.
+	Key string
+	// RequiresRestart reports whether the setting is only read at startup.
+	RequiresRestart bool
+	// Secret reports whether the value must not be shown.
+	Secret bool
+
+	index int
+}
+
+var (
+	fieldsOnce sync.Once
+	fields     []Field
+)
+
+// secretType identifies the settings held in a Secret.
+var secretType = reflect.TypeOf(Secret{})
+
+// Fields returns the settings of Config in declaration order.
+func Fields() []Field {
+	fieldsOnce.Do(func() {
+		configType := reflect.TypeOf(Config{})
+
+		for i := 0; i < configType.NumField(); i++ {
+			structField := configType.Field(i)
+
+			key := structField.Tag.Get("env")
+			if key == "" {
+				continue
+			}
+
+			fields = append(fields, Field{
+				Key:             key,
+				RequiresRestart: structField.Tag.Get("reload") == "restart",
+				Secret:          structField.Type == secretType,
+				index:           i,
+			})
+		}
+	})
+
+	return fields
+}
+
+// Value returns the setting's value in c, formatted the way it would be written in an
+// environment variable. Secret values are redacted.
+func (f Field) Value(c *Config) string {
+	switch v := reflect.ValueOf(c).Elem().Field(f.index).Interface().(type) {
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Change is a setting that differs between two configurations.
+type Change struct {
+	Key             string
+	Old             string
+	New             string
+	RequiresRestart bool
+}
+
+// Reconcile compares a freshly loaded configuration with the running one. It returns the
+// configuration to apply, which takes the loaded values for settings that can change at
+// runtime and keeps the running values for settings that need a restart, and every change.
+func Reconcile(running, loaded *Config) (*Config, []Change) {
+	applied := *loaded
+	appliedValue := reflect.ValueOf(&applied).Elem()
+	runningValue := reflect.ValueOf(running).Elem()
+
+	// Settings kept from the running configuration keep their source too.
+	applied.sources = make(map[string]Source, len(loaded.sources))
+	for key, source := range loaded.sources {
+		applied.sources[key] = source
+	}
+
+	var changes []Change
+
+	for _, field := range Fields() {
+		if field.RequiresRestart {
+			applied.sources[field.Key] = running.Source(field.Key)
+		}
+
+		if reflect.DeepEqual(runningValue.Field(field.index).Interface(), appliedValue.Field(field.index).Interface()) {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Key:             field.Key,
+			Old:             field.Value(running),
+			New:             field.Value(loaded),
+			RequiresRestart: field.RequiresRestart,
+		})
+
+		if field.RequiresRestart {
+			appliedValue.Field(field.index).Set(runningValue.Field(field.index))
+		}
+	}
+
+	return &applied, changes
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// redacted replaces the value of secret settings.
+const redacted = "[redacted]"
+
+// fileSuffix is appended to the key of a secret to name the file it can be read from,
+// so DISCORD_TOKEN_FILE=/run/secrets/discord_token loads DISCORD_TOKEN from that file.
+const fileSuffix = "_FILE"
+
+// Secret holds a sensitive setting such as a token. It redacts itself when printed with
+// the fmt package, logged with slog or marshaled, so it cannot leak by accident; Reveal
+// returns the actual value.
+type Secret struct {
+	value string
+}
+
+// NewSecret wraps value in a Secret.
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() string {
+	return s.value
+}
+
+// IsSet reports whether the secret has a value.
+func (s Secret) IsSet() bool {
+	return s.value != ""
+}
+
+// String returns "[redacted]", or an empty string when the secret is not set.
+func (s Secret) String() string {
+	if !s.IsSet() {
+		return ""
+	}
+
+	return redacted
+}
+
+// GoString redacts the secret in %#v output.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+// LogValue redacts the secret in slog output.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// MarshalText redacts the secret in JSON, YAML and other text encodings.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// secret returns the secret value of key. It is read from the environment variable
+// itself or, when key_FILE is set instead, from the file it names, which keeps the
+// value out of the process environment. Setting both is a problem.
+func (l *layers) secret(key string) Secret {
+	value := l.get(key)
+
+	path := l.get(key + fileSuffix)
+	if path == "" {
+		return NewSecret(value)
+	}
+
+	if value != "" {
+		l.problems = append(l.problems, Problem{
+			Key:     key,
+			Message: fmt.Sprintf("set either %s or %s%s, not both", key, key, fileSuffix),
+		})
+
+		return NewSecret(value)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.invalid(key+fileSuffix, path, fmt.Sprintf("cannot read secret file: %v", err))
+		return Secret{}
+	}
+
+	l.sources[key] = SourceSecretFile
+
+	// Secret files usually end with a newline, which is not part of the value.
+	return NewSecret(strings.TrimSpace(string(data)))
+}
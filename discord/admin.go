@@ -0,0 +1,185 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+)
+
+// ReloadFunc reloads and applies the configuration, returning every setting that changed.
+type ReloadFunc func() ([]config.Change, error)
+
+// SetReloadFunc installs the function the !reload command uses to reload the configuration.
+func (b *Bot) SetReloadFunc(reload ReloadFunc) {
+	b.reload = reload
+}
+
+// rejection is returned by command wrappers that refused to run a command and already told
+// the user why. Its value is the reason counted in rejected_commands_total.
+type rejection string
+
+func (r rejection) Error() string {
+	return "command rejected: " + string(r)
+}
+
+// ownerOnly restricts a command to the bot owners configured in OWNER_IDS.
+func (b *Bot) ownerOnly(handler CommandHandler) CommandHandler {
+	return func(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+		if !b.Config().IsOwner(m.Author.ID) {
+			logger := b.authorLogger(m)
+			logger.WarnContext(ctx, "Rejected owner-only command")
+
+			b.sendErrorMessage(ctx, s, m.ChannelID, i18n.FromContext(ctx).T("admin.owner_only"))
+			b.strike(ctx, s, m, "owner-only command")
+
+			return rejection("owner_only")
+		}
+
+		return handler(ctx, s, m, args)
+	}
+}
+
+// adminOnly restricts a command to server members with the Manage Server permission, and
+// to the bot owners. Such commands cannot be used in direct messages.
+func (b *Bot) adminOnly(handler CommandHandler) CommandHandler {
+	return b.guildRestricted(handler, false)
+}
+
+// moderatorOnly restricts a command like adminOnly, but also lets members with the
+// guild's moderation.mod_role use it.
+func (b *Bot) moderatorOnly(handler CommandHandler) CommandHandler {
+	return b.guildRestricted(handler, true)
+}
+
+// guildRestricted restricts a command to administrators and owners, and to moderators
+// when moderators is set.
+func (b *Bot) guildRestricted(handler CommandHandler, moderators bool) CommandHandler {
+	return func(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+		if m.GuildID == "" {
+			b.sendErrorMessage(ctx, s, m.ChannelID, i18n.FromContext(ctx).T("admin.server_only"))
+			return rejection("server_only")
+		}
+
+		if moderators && hasModRole(m, b.guildSettings(m.GuildID)) {
+			return handler(ctx, s, m, args)
+		}
+
+		allowed, err := b.administrator(ctx, s, m, b.Config())
+		if err != nil {
+			return errors.NewDiscordError("failed to check member permissions", err)
+		}
+
+		if !allowed {
+			logger := b.authorLogger(m)
+			logger.WarnContext(ctx, "Rejected admin-only command", "guild_id", m.GuildID)
+
+			message := "admin.manage_server"
+			if moderators {
+				message = "admin.moderator"
+			}
+
+			b.sendErrorMessage(ctx, s, m.ChannelID, i18n.FromContext(ctx).T(message))
+			b.strike(ctx, s, m, "admin-only command")
+
+			return rejection("admin_only")
+		}
+
+		return handler(ctx, s, m, args)
+	}
+}
+
+// handleReload handles the owner-only !reload command.
+func (b *Bot) handleReload(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, _ []string) error {
+	logger := b.commandLogger(m, "reload")
+	logger.InfoContext(ctx, "Reloading configuration")
+
+	loc := i18n.FromContext(ctx)
+
+	if b.reload == nil {
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("reload.unavailable"))
+		return nil
+	}
+
+	changes, err := b.reload()
+	if err != nil {
+		b.sendErrorMessage(ctx, s, m.ChannelID, reloadErrorMessage(loc, err))
+		return nil
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, reloadEmbed(loc, changes), discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send reload report", err)
+	}
+
+	return nil
+}
+
+// reloadEmbed summarizes the applied and pending changes of a reload.
+func reloadEmbed(loc *i18n.Localizer, changes []config.Change) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: loc.T("reload.title"),
+		Color: 0x2ECC71, // Green color.
+	}
+
+	if len(changes) == 0 {
+		embed.Description = loc.T("reload.unchanged")
+		return embed
+	}
+
+	var applied, pending []string
+
+	for _, change := range changes {
+		line := fmt.Sprintf("`%s`: `%s` → `%s`", change.Key, change.Old, change.New)
+		if change.RequiresRestart {
+			pending = append(pending, line)
+		} else {
+			applied = append(applied, line)
+		}
+	}
+
+	if len(applied) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  loc.T("reload.applied"),
+			Value: truncateField(strings.Join(applied, "\n")),
+		})
+	}
+
+	if len(pending) > 0 {
+		embed.Color = 0xF39C12 // Orange color.
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  loc.T("reload.pending"),
+			Value: truncateField(strings.Join(pending, "\n")),
+		})
+	}
+
+	return embed
+}
+
+// reloadErrorMessage describes why a reload was rejected. The running configuration is unchanged.
+func reloadErrorMessage(loc *i18n.Localizer, err error) string {
+	problems := config.Problems(err)
+	if len(problems) == 0 {
+		return loc.T("reload.failed", err.Error())
+	}
+
+	lines := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		lines = append(lines, "• "+problem.Error())
+	}
+
+	return truncateField(loc.T("reload.failed_problems", strings.Join(lines, "\n")))
+}
+
+// truncateField shortens text to fit in an embed field.
+func truncateField(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxEmbedFieldLength {
+		return text
+	}
+
+	return string(runes[:maxEmbedFieldLength-1]) + "…"
+}
@@ -0,0 +1,111 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/alerting"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+)
+
+// Alert embed colors.
+const (
+	alertFiringColor   = 0xE74C3C
+	alertResolvedColor = 0x2ECC71
+)
+
+// Notify delivers an alert to the configured alert channel, in the language of its server,
+// or as a direct message to every bot owner, in their language, when no channel is
+// configured. It implements alerting.Notifier.
+func (b *Bot) Notify(ctx context.Context, alert alerting.Alert) error {
+	cfg := b.Config()
+
+	if cfg.AlertChannelID != "" {
+		embed := alertEmbed(b.channelLocalizer(cfg.AlertChannelID), alert, cfg.BotName)
+		if _, err := b.session.ChannelMessageSendEmbed(cfg.AlertChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+			return errors.NewDiscordError("failed to send alert to channel "+cfg.AlertChannelID, err)
+		}
+
+		return nil
+	}
+
+	var firstErr error
+
+	for _, ownerID := range cfg.OwnerIDs {
+		embed := alertEmbed(b.localizer(b.settings.Defaults(), ownerID, ""), alert, cfg.BotName)
+		if err := b.sendDirectEmbed(ctx, ownerID, embed); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// channelLocalizer returns the localizer of the guild a channel belongs to, or the default
+// one when the channel is unknown.
+func (b *Bot) channelLocalizer(channelID string) *i18n.Localizer {
+	channel, err := b.session.State.Channel(channelID)
+	if err != nil {
+		return i18n.Default()
+	}
+
+	return i18n.Resolve(b.guildSettings(channel.GuildID).Locale)
+}
+
+// sendDirectEmbed sends an embed as a direct message to a user.
+func (b *Bot) sendDirectEmbed(ctx context.Context, userID string, embed *discordgo.MessageEmbed) error {
+	channel, err := b.session.UserChannelCreate(userID, discordgo.WithContext(ctx))
+	if err != nil {
+		return errors.NewDiscordError("failed to open direct message channel with "+userID, err)
+	}
+
+	if _, err := b.session.ChannelMessageSendEmbed(channel.ID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send direct message to "+userID, err)
+	}
+
+	return nil
+}
+
+// alertEmbed builds the embed announcing an alert or its resolution.
+func alertEmbed(loc *i18n.Localizer, alert alerting.Alert, botName string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Description: alert.Description,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   loc.T("alert.observed"),
+				Value:  alert.Detail,
+				Inline: false,
+			},
+			{
+				Name:   loc.T("alert.started"),
+				Value:  fmt.Sprintf("<t:%d:R>", alert.StartedAt.Unix()),
+				Inline: true,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: loc.T("alert.footer", botName, alert.Rule),
+		},
+	}
+
+	if alert.State == alerting.StateResolved {
+		embed.Title = loc.T("alert.resolved", alert.Rule)
+		embed.Color = alertResolvedColor
+		embed.Timestamp = alert.ResolvedAt.Format(time.RFC3339)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   loc.T("alert.lasted"),
+			Value:  loc.Duration(alert.Duration()),
+			Inline: true,
+		})
+
+		return embed
+	}
+
+	embed.Title = loc.T("alert.firing", alert.Rule)
+	embed.Color = alertFiringColor
+	embed.Timestamp = time.Now().Format(time.RFC3339)
+
+	return embed
+}
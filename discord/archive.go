@@ -0,0 +1,206 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/archive"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+)
+
+// maxArchiveChanges is the number of changes listed in an import report.
+const maxArchiveChanges = 20
+
+// handleExport handles the !export command, which sends everything the bot stores for the
+// server as a JSON archive.
+func (b *Bot) handleExport(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, _ []string) error {
+	logger := b.commandLogger(m, "export").With("guild_id", m.GuildID)
+
+	exported, err := archive.Export(b.store, m.GuildID)
+	if err != nil {
+		return err
+	}
+
+	data, err := exported.Marshal()
+	if err != nil {
+		return err
+	}
+
+	logger.InfoContext(ctx, "Exported guild data", "features", len(exported.Features))
+
+	_, err = s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content: i18n.FromContext(ctx).T("archive.exported", exported.ExportedAt.Unix(), b.prefix(m.GuildID)),
+		Files: []*discordgo.File{
+			{Name: fmt.Sprintf("guild-%s.json", m.GuildID), ContentType: "application/json", Reader: bytes.NewReader(data)},
+		},
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return errors.NewDiscordError("failed to send export", err)
+	}
+
+	return nil
+}
+
+// handleImport handles the !import command. With an archive attached, it shows what
+// importing would change; with "apply", it replaces the server's data with the archive.
+func (b *Bot) handleImport(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	prefix := b.prefix(m.GuildID)
+	apply := len(args) == 1 && strings.EqualFold(args[0], "apply")
+
+	if (len(args) > 0 && !apply) || len(m.Attachments) != 1 {
+		b.sendErrorMessage(ctx, s, m.ChannelID, i18n.FromContext(ctx).T("archive.usage", prefix))
+		return nil
+	}
+
+	embed, err := b.importArchive(ctx, s, m, apply)
+	if err != nil {
+		if message, ok := i18n.FromContext(ctx).Error(err); ok {
+			b.sendErrorMessage(ctx, s, m.ChannelID, truncateField(message))
+			return nil
+		}
+
+		return err
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send import report", err)
+	}
+
+	return nil
+}
+
+// importArchive downloads and validates the attached archive, then previews or applies
+// the import and returns its report.
+func (b *Bot) importArchive(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, apply bool) (*discordgo.MessageEmbed, error) {
+	loc := i18n.FromContext(ctx)
+
+	data, err := downloadAttachment(ctx, s, m.Attachments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := archive.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !apply {
+		changes, err := archive.Plan(b.store, m.GuildID, imported)
+		if err != nil {
+			return nil, err
+		}
+
+		return importEmbed(loc, changes, false, b.prefix(m.GuildID)), nil
+	}
+
+	changes, err := archive.Import(b.store, m.GuildID, imported)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the cached copies of the replaced data.
+	b.settings.Invalidate(m.GuildID)
+	b.rules.Invalidate(m.GuildID)
+	b.blocklist.Invalidate(m.GuildID)
+
+	logger := b.commandLogger(m, "import").With("guild_id", m.GuildID)
+	logger.InfoContext(ctx, "Imported guild data", "source_guild_id", imported.GuildID, "changes", len(changes))
+
+	b.logSettingsChange(ctx, s, m, func(loc *i18n.Localizer) string {
+		return loc.N("archive.imported", int64(len(changes)), loc.Date(imported.ExportedAt), loc.Int(int64(len(changes))))
+	})
+
+	return importEmbed(loc, changes, true, b.prefix(m.GuildID)), nil
+}
+
+// importEmbed lists the changes of an import, or of its preview when applied is false.
+func importEmbed(loc *i18n.Localizer, changes []archive.Change, applied bool, prefix string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: loc.T("archive.preview_title"),
+		Color: 0x3498DB, // Blue color.
+	}
+
+	if applied {
+		embed.Title = loc.T("archive.applied_title")
+		embed.Color = 0x2ECC71 // Green color.
+	}
+
+	count := int64(len(changes))
+
+	switch {
+	case count == 0:
+		embed.Description = loc.T("archive.unchanged")
+	case applied:
+		embed.Description = loc.N("archive.applied", count, loc.Int(count))
+	default:
+		embed.Description = loc.N("archive.preview", count, loc.Int(count), prefix)
+	}
+
+	lines := make([]string, 0, len(changes))
+
+	for i, change := range changes {
+		if i == maxArchiveChanges {
+			lines = append(lines, loc.T("archive.more", loc.Int(int64(len(changes)-maxArchiveChanges))))
+			break
+		}
+
+		var line string
+
+		switch change.Kind {
+		case archive.ChangeAdded:
+			line = fmt.Sprintf("➕ `%s.%s`: `%s`", change.Feature, change.Key, change.New)
+		case archive.ChangeChanged:
+			line = fmt.Sprintf("✏️ `%s.%s`: `%s` → `%s`", change.Feature, change.Key, change.Old, change.New)
+		case archive.ChangeRemoved:
+			line = fmt.Sprintf("➖ `%s.%s`", change.Feature, change.Key)
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(lines) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  loc.T("archive.changes"),
+			Value: truncateField(strings.Join(lines, "\n")),
+		})
+	}
+
+	return embed
+}
+
+// downloadAttachment downloads an attached archive, refusing files larger than archive.MaxSize.
+func downloadAttachment(ctx context.Context, s *discordgo.Session, attachment *discordgo.MessageAttachment) ([]byte, error) {
+	if attachment.Size > archive.MaxSize {
+		loc := i18n.FromContext(ctx)
+		return nil, errors.NewValidationError(loc.T("archive.too_large", loc.Int(archive.MaxSize>>10)))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attachment.URL, nil)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to create attachment request", err)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, errors.NewNetworkError("failed to download attachment", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.FromHTTPStatus(resp.StatusCode, "failed to download attachment")
+	}
+
+	// Read one byte more than allowed, so archive.Parse sees oversized files.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, archive.MaxSize+1))
+	if err != nil {
+		return nil, errors.NewNetworkError("failed to download attachment", err)
+	}
+
+	return data, nil
+}
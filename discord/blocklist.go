@@ -0,0 +1,256 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/blocklist"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/logging"
+)
+
+// ignored reports whether a user is blocked in a guild, in which case the bot ignores their
+// messages and interactions. guildID is empty for direct messages. Owners are never blocked.
+func (b *Bot) ignored(ctx context.Context, guildID, userID string, cfg *config.Config) bool {
+	if cfg.IsOwner(userID) {
+		return false
+	}
+
+	entry, blocked := b.blocklist.Blocked(guildID, userID)
+	if blocked {
+		logger := logging.WithComponent("discord").With("user_id", userID, "guild_id", guildID)
+		logger.DebugContext(ctx, "Ignoring blocked user", "blocked_kind", entry.Kind, "blocked_id", entry.ID,
+			"blocklist_guild_id", entry.GuildID)
+	}
+
+	return blocked
+}
+
+// strike records that the author of a message used a command they are not allowed to use,
+// an admin or owner command without the permission, or ran a command within
+// ABUSE_COMMAND_COOLDOWN of their previous one. Users who collect too many strikes within
+// ABUSE_STRIKE_WINDOW are blocked globally for ABUSE_BLOCK_DURATION. Unknown commands,
+// commands blocked by rules and failing handlers are not abuse and do not count.
+func (b *Bot) strike(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, reason string) {
+	cfg := b.Config()
+	if cfg.IsOwner(m.Author.ID) || !b.detector.Strike(m.Author.ID, cfg.AbuseStrikeLimit, cfg.AbuseStrikeWindow) {
+		return
+	}
+
+	now := time.Now().UTC()
+	entry := blocklist.Entry{
+		Kind:      blocklist.KindUser,
+		ID:        m.Author.ID,
+		Reason:    fmt.Sprintf("automatic: %d strikes within %s, last: %s", cfg.AbuseStrikeLimit, cfg.AbuseStrikeWindow, reason),
+		AddedAt:   now,
+		ExpiresAt: now.Add(cfg.AbuseBlockDuration),
+	}
+
+	logger := logging.WithComponent("discord").With("user_id", m.Author.ID, "username", m.Author.Username, "guild_id", m.GuildID)
+
+	if err := b.blocklist.Add(entry); err != nil {
+		logging.LogErrorContext(ctx, logger, err, "Failed to block abusive user")
+		return
+	}
+
+	logger.WarnContext(ctx, "Temporarily blocked user for repeated strikes",
+		"strikes", cfg.AbuseStrikeLimit, "duration", cfg.AbuseBlockDuration, "last_strike", reason)
+
+	loc := i18n.FromContext(ctx)
+	b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("blocklist.auto_blocked", m.Author.ID, loc.Duration(cfg.AbuseBlockDuration)))
+}
+
+// handleGlobalBlocklist handles the owner-only !blocklist command, which manages the global
+// blocklist of users and guilds.
+func (b *Bot) handleGlobalBlocklist(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	loc := i18n.FromContext(ctx)
+	usage := loc.T("blocklist.usage_global", b.prefix(m.GuildID))
+
+	if len(args) == 1 && strings.EqualFold(args[0], "list") {
+		return b.sendBlocklist(ctx, s, m, "")
+	}
+
+	if len(args) < 3 {
+		b.sendErrorMessage(ctx, s, m.ChannelID, usage)
+		return nil
+	}
+
+	kind, err := blocklist.ParseKind(args[1])
+	if err != nil {
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("blocklist.unknown_kind", args[1]))
+		return nil
+	}
+
+	return b.changeBlocklist(ctx, s, m, "", kind, args[0], args[2:], usage)
+}
+
+// handleBlock handles the !block command, with which server administrators manage the
+// blocklist of their server.
+func (b *Bot) handleBlock(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	usage := i18n.FromContext(ctx).T("blocklist.usage", b.prefix(m.GuildID))
+
+	if len(args) == 1 && strings.EqualFold(args[0], "list") {
+		return b.sendBlocklist(ctx, s, m, m.GuildID)
+	}
+
+	if len(args) < 2 {
+		b.sendErrorMessage(ctx, s, m.ChannelID, usage)
+		return nil
+	}
+
+	return b.changeBlocklist(ctx, s, m, m.GuildID, blocklist.KindUser, args[0], args[1:], usage)
+}
+
+// changeBlocklist adds or removes an entry of the blocklist of a guild, or of the global
+// blocklist when guildID is empty. args holds the ID, then the optional duration and reason.
+func (b *Bot) changeBlocklist(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, guildID string, kind blocklist.Kind, action string, args []string, usage string) error {
+	loc := i18n.FromContext(ctx)
+	id := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(args[0], "<@"), "!"), ">")
+	cfg := b.Config()
+
+	switch {
+	case kind == blocklist.KindUser && (cfg.IsOwner(id) || id == s.State.User.ID):
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("blocklist.protected"))
+		return nil
+	case !config.IsSnowflake(id):
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("blocklist.invalid_"+string(kind), args[0]))
+		return nil
+	}
+
+	// describe renders the change, for the reply and the guild's log channel.
+	var describe func(loc *i18n.Localizer) string
+
+	switch strings.ToLower(action) {
+	case "add":
+		entry := blocklist.Entry{Kind: kind, ID: id, GuildID: guildID, AddedBy: m.Author.ID, AddedAt: time.Now().UTC()}
+		args = args[1:]
+
+		if len(args) > 0 {
+			if duration, err := time.ParseDuration(args[0]); err == nil && duration > 0 {
+				entry.ExpiresAt = entry.AddedAt.Add(duration)
+				args = args[1:]
+			}
+		}
+
+		entry.Reason = strings.Join(args, " ")
+
+		if err := b.blocklist.Add(entry); err != nil {
+			return err
+		}
+
+		describe = func(loc *i18n.Localizer) string {
+			return loc.T("blocklist.blocked", blockedDisplay(loc, entry.Kind, entry.ID), blockDuration(loc, entry))
+		}
+	case "remove":
+		if len(args) != 1 {
+			b.sendErrorMessage(ctx, s, m.ChannelID, usage)
+			return nil
+		}
+
+		removed, err := b.blocklist.Remove(guildID, kind, id)
+		if err != nil {
+			return err
+		}
+
+		if !removed {
+			b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("blocklist.not_blocked", blockedDisplay(loc, kind, id)))
+			return nil
+		}
+
+		describe = func(loc *i18n.Localizer) string {
+			return loc.T("blocklist.unblocked", blockedDisplay(loc, kind, id))
+		}
+	default:
+		b.sendErrorMessage(ctx, s, m.ChannelID, usage)
+		return nil
+	}
+
+	command := "block"
+	if guildID == "" {
+		command = "blocklist"
+	}
+
+	logger := b.commandLogger(m, command).With("guild_id", m.GuildID)
+	logger.InfoContext(ctx, "Blocklist changed", "action", strings.ToLower(action), "blocked_kind", kind,
+		"blocked_id", id, "global", guildID == "")
+
+	if guildID != "" {
+		b.logSettingsChange(ctx, s, m, describe)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("blocklist.title"),
+		Description: describe(loc),
+		Color:       0x2ECC71, // Green color.
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send blocklist change", err)
+	}
+
+	return nil
+}
+
+// sendBlocklist sends the active entries of the blocklist of a guild, or of the global
+// blocklist when guildID is empty.
+func (b *Bot) sendBlocklist(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, guildID string) error {
+	entries, err := b.blocklist.List(guildID)
+	if err != nil {
+		return err
+	}
+
+	loc := i18n.FromContext(ctx)
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("blocklist.server_title"),
+		Description: loc.T("blocklist.empty"),
+		Color:       0x3498DB, // Blue color.
+	}
+
+	if guildID == "" {
+		embed.Title = loc.T("blocklist.global_title")
+	}
+
+	if len(entries) > 0 {
+		lines := make([]string, 0, len(entries))
+
+		for _, entry := range entries {
+			line := blockedDisplay(loc, entry.Kind, entry.ID) + " " + blockDuration(loc, entry)
+			if entry.Reason != "" {
+				line += ": " + entry.Reason
+			}
+
+			lines = append(lines, line)
+		}
+
+		embed.Description = truncateField(strings.Join(lines, "\n"))
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send blocklist", err)
+	}
+
+	return nil
+}
+
+// blockedDisplay formats a blocked ID.
+func blockedDisplay(loc *i18n.Localizer, kind blocklist.Kind, id string) string {
+	if kind == blocklist.KindUser {
+		return "<@" + id + ">"
+	}
+
+	return loc.T("blocklist.guild", id)
+}
+
+// blockDuration describes how long a block lasts.
+func blockDuration(loc *i18n.Localizer, entry blocklist.Entry) string {
+	if entry.ExpiresAt.IsZero() {
+		return loc.T("blocklist.permanently")
+	}
+
+	return loc.T("blocklist.until", entry.ExpiresAt.Unix())
+}
@@ -2,56 +2,149 @@
 package discord
 
 import (
+	"context"
+	stdErrors "errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/blocklist"
 	"github.com/dunamismax/discogo/config"
 	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
 	"github.com/dunamismax/discogo/logging"
 	"github.com/dunamismax/discogo/metrics"
+	"github.com/dunamismax/discogo/privacy"
+	"github.com/dunamismax/discogo/rules"
+	"github.com/dunamismax/discogo/settings"
+	"github.com/dunamismax/discogo/storage"
+	"github.com/dunamismax/discogo/storage/migrations"
+	"github.com/dunamismax/discogo/tracing"
 )
 
+// gatewayLatencyInterval is how often the gateway heartbeat latency is sampled.
+const gatewayLatencyInterval = 15 * time.Second
+
+var commandRejections = metrics.RegisterCounter("rejected_commands_total",
+	"Commands rejected before running, such as ones used without the permission or during a cooldown.",
+	"command", "reason")
+
 // Bot represents a Discord bot instance with all necessary components.
 type Bot struct {
-	session         *discordgo.Session
-	config          *config.Config
-	commandHandlers map[string]CommandHandler
+	session           *discordgo.Session
+	config            atomic.Pointer[config.Config]
+	maintenance       atomic.Pointer[Maintenance]
+	presenceIndex     atomic.Uint64
+	alertsMutex       sync.Mutex
+	firingAlerts      map[string]bool
+	store             storage.Store
+	settings          *settings.Store
+	privacy           *privacy.Store
+	rules             *rules.Store
+	blocklist         *blocklist.Store
+	locales           *i18n.Store
+	detector          *blocklist.Detector
+	cooldowns         *cooldowns
+	commandHandlers   map[string]CommandHandler
+	commandCategories map[string]string
+	reload            ReloadFunc
+	stop              chan struct{}
+	wg                sync.WaitGroup
 }
 
 // CommandHandler represents a function that handles Discord bot commands.
-type CommandHandler func(s *discordgo.Session, m *discordgo.MessageCreate, args []string) error
+// The context carries the trace span of the command and is bounded by the request timeout;
+// pass it to REST calls with discordgo.WithContext.
+type CommandHandler func(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error
 
-// NewBot creates a new Discord bot instance.
-func NewBot(cfg *config.Config) (*Bot, error) {
-	session, err := discordgo.New("Bot " + cfg.DiscordToken)
+// NewBot creates a new Discord bot instance that keeps its persistent data in store.
+func NewBot(cfg *config.Config, store storage.Store) (*Bot, error) {
+	session, err := discordgo.New("Bot " + cfg.DiscordToken.Reveal())
 	if err != nil {
 		return nil, errors.NewDiscordError("failed to create Discord session", err)
 	}
 
 	bot := &Bot{
-		session:         session,
-		config:          cfg,
-		commandHandlers: make(map[string]CommandHandler),
+		session:           session,
+		store:             store,
+		commandHandlers:   make(map[string]CommandHandler),
+		commandCategories: make(map[string]string),
+		firingAlerts:      make(map[string]bool),
+		stop:              make(chan struct{}),
 	}
+	bot.config.Store(cfg)
+	bot.settings = settings.NewStore(store, bot.Config)
+	bot.privacy = privacy.NewStore(store)
+	bot.rules = rules.NewStore(store)
+	bot.blocklist = blocklist.NewStore(store)
+	bot.detector = blocklist.NewDetector()
+	bot.cooldowns = newCooldowns()
+	bot.locales = i18n.NewStore(store)
+	bot.applyMaintenanceConfig(nil, cfg)
 
 	// Register command handlers.
 	bot.registerCommands()
 
+	// Record metrics and trace spans for every REST request.
+	session.Client.Transport = tracing.NewTransport(metrics.NewTransport(session.Client.Transport))
+
 	// Add message handler.
 	session.AddHandler(bot.messageCreate)
 
+	// Answer interactions, which are not commands of this bot.
+	session.AddHandler(bot.interactionCreate)
+
+	// Track gateway connectivity and rate limits for metrics and alerting.
+	session.AddHandler(bot.onConnect)
+	session.AddHandler(bot.onDisconnect)
+	session.AddHandler(bot.onRateLimit)
+
+	// Set the presence of every new gateway session.
+	session.AddHandler(bot.onReady)
+
 	// Set intents.
-	session.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages
+	// Guilds keeps the guild list in the state up to date, for the guild count of statuses.
+	session.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages
 
 	return bot, nil
 }
 
+// Config returns the configuration the bot is currently running with.
+func (b *Bot) Config() *config.Config {
+	return b.config.Load()
+}
+
+// Store returns the storage the bot keeps its persistent data in.
+func (b *Bot) Store() storage.Store {
+	return b.store
+}
+
+// ApplyConfig atomically replaces the running configuration. Messages already being
+// handled finish with the configuration they started with. Maintenance mode follows the
+// new configuration when its maintenance settings changed, and changed statuses are shown
+// right away.
+func (b *Bot) ApplyConfig(cfg *config.Config) {
+	previous := b.config.Swap(cfg)
+	b.applyMaintenanceConfig(previous, cfg)
+
+	if !slices.Equal(previous.PresenceStatuses, cfg.PresenceStatuses) {
+		b.updatePresence()
+	}
+}
+
 // Start starts the Discord bot.
 func (b *Bot) Start() error {
 	logger := logging.WithComponent("discord")
-	logger.Info("Starting bot", "bot_name", b.config.BotName)
+	logger.Info("Starting bot", "bot_name", b.Config().BotName)
+
+	// Bring the stored data up to date before any event is handled.
+	if err := b.migrateStorage(); err != nil {
+		return err
+	}
 
 	err := b.session.Open()
 	if err != nil {
@@ -60,13 +153,39 @@ func (b *Bot) Start() error {
 
 	logger.Info("Bot is now running", "username", b.session.State.User.Username)
 
+	b.wg.Add(1)
+
+	go b.monitorGatewayLatency()
+
+	b.wg.Add(1)
+
+	go b.rotatePresence()
+
+	return nil
+}
+
+// migrateStorage applies the pending storage migrations. It fails when the storage was
+// migrated by a newer version of the bot, which this version cannot read safely.
+func (b *Bot) migrateStorage() error {
+	migrator, err := storage.NewMigrator(b.store, migrations.All, b.Config().StorageBackupDir)
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Up(); err != nil {
+		return errors.NewInternalError("failed to migrate storage", err)
+	}
+
 	return nil
 }
 
 // Stop stops the Discord bot.
 func (b *Bot) Stop() error {
 	logger := logging.WithComponent("discord")
-	logger.Info("Stopping bot", "bot_name", b.config.BotName)
+	logger.Info("Stopping bot", "bot_name", b.Config().BotName)
+
+	close(b.stop)
+	b.wg.Wait()
 
 	if err := b.session.Close(); err != nil {
 		return errors.NewDiscordError("failed to close Discord session", err)
@@ -75,11 +194,78 @@ func (b *Bot) Stop() error {
 	return nil
 }
 
+// monitorGatewayLatency periodically records the gateway heartbeat latency until the bot stops.
+func (b *Bot) monitorGatewayLatency() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(gatewayLatencyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			if latency := b.session.HeartbeatLatency(); latency > 0 {
+				metrics.RecordGatewayLatency(latency)
+			}
+		}
+	}
+}
+
+// onConnect records that the gateway connection is up.
+func (b *Bot) onConnect(_ *discordgo.Session, _ *discordgo.Connect) {
+	metrics.RecordGatewayConnected(true)
+}
+
+// onDisconnect records that the gateway connection was lost.
+func (b *Bot) onDisconnect(_ *discordgo.Session, _ *discordgo.Disconnect) {
+	logger := logging.WithComponent("discord")
+	logger.Warn("Gateway disconnected")
+
+	metrics.RecordGatewayConnected(false)
+}
+
+// onRateLimit records a REST request that hit a Discord rate limit.
+func (b *Bot) onRateLimit(_ *discordgo.Session, r *discordgo.RateLimit) {
+	logger := logging.WithComponent("discord")
+	logger.Warn("Rate limited by Discord", "url", r.URL, "bucket", r.Bucket, "retry_after", r.RetryAfter)
+
+	metrics.RecordError(errors.NewRateLimitError("rate limited on "+r.URL, int(r.RetryAfter.Seconds())))
+}
+
+// Command categories, which command rules can allow or deny as a whole.
+const (
+	categoryGeneral = "general"
+	categoryAdmin   = "admin"
+	categoryOwner   = "owner"
+)
+
+// commandCategoryNames lists every command category.
+var commandCategoryNames = []string{categoryGeneral, categoryAdmin, categoryOwner}
+
 // registerCommands registers all command handlers.
 func (b *Bot) registerCommands() {
-	b.commandHandlers["ping"] = b.handlePing
-	b.commandHandlers["help"] = b.handleHelp
-	b.commandHandlers["stats"] = b.handleStats
+	b.registerCommand("ping", categoryGeneral, b.handlePing)
+	b.registerCommand("help", categoryGeneral, b.handleHelp)
+	b.registerCommand("stats", categoryGeneral, b.handleStats)
+	b.registerCommand("privacy", categoryGeneral, b.handlePrivacy)
+	b.registerCommand("language", categoryGeneral, b.handleLanguage)
+	b.registerCommand("reload", categoryOwner, b.ownerOnly(b.handleReload))
+	b.registerCommand("config", categoryOwner, b.ownerOnly(b.handleConfig))
+	b.registerCommand("blocklist", categoryOwner, b.ownerOnly(b.handleGlobalBlocklist))
+	b.registerCommand("maintenance", categoryOwner, b.ownerOnly(b.handleMaintenance))
+	b.registerCommand("settings", categoryAdmin, b.adminOnly(b.handleSettings))
+	b.registerCommand("rules", categoryAdmin, b.adminOnly(b.handleRules))
+	b.registerCommand("block", categoryAdmin, b.moderatorOnly(b.handleBlock))
+	b.registerCommand("export", categoryAdmin, b.adminOnly(b.handleExport))
+	b.registerCommand("import", categoryAdmin, b.adminOnly(b.handleImport))
+}
+
+// registerCommand registers the handler of a command in a category.
+func (b *Bot) registerCommand(name, category string, handler CommandHandler) {
+	b.commandHandlers[name] = handler
+	b.commandCategories[name] = category
 }
 
 // messageCreate handles incoming messages.
@@ -89,221 +275,315 @@ func (b *Bot) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
-	// Check if message starts with command prefix.
-	if !strings.HasPrefix(m.Content, b.config.CommandPrefix) {
+	// Use one configuration snapshot for the whole message, even if it is reloaded meanwhile.
+	cfg := b.Config()
+	guild := b.guildSettings(m.GuildID)
+
+	// The moderation filters apply to every message, not only commands; the notice of a
+	// deleted message is in its author's language.
+	moderationCtx := i18n.NewContext(context.Background(), b.localizer(guild, m.Author.ID, ""))
+	if b.moderate(moderationCtx, s, m, guild, cfg) {
+		return
+	}
+
+	// Check if message starts with the guild's command prefix.
+	if !strings.HasPrefix(m.Content, guild.Prefix) {
+		return
+	}
+
+	// Ignore blocked users and guilds.
+	if b.ignored(context.Background(), m.GuildID, m.Author.ID, cfg) {
+		return
+	}
+
+	ctx, span := tracing.Start(context.Background(), "discord.message", tracing.SpanKindServer,
+		tracing.String("discord.message_id", m.ID),
+		tracing.String("discord.channel_id", m.ChannelID),
+		tracing.String("discord.guild_id", m.GuildID),
+	)
+	defer span.End()
+
+	// Like the logs, traces only identify users who did not opt out of message logs.
+	if !b.privacy.OptedOut(m.Author.ID, privacy.MessageLogs) {
+		span.SetAttributes(tracing.String("discord.user_id", m.Author.ID))
+	}
+
+	// Replies to the command are in the author's language.
+	ctx = i18n.NewContext(ctx, b.localizer(guild, m.Author.ID, ""))
+
+	b.dispatch(ctx, s, m, cfg, guild)
+}
+
+// interactionCreate handles interactions. Commands are messages, so the bot answers
+// application commands, such as ones left registered by an earlier version, by pointing to
+// its prefix. Blocked users and guilds are ignored here as well.
+func (b *Bot) interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+
+	if user == nil || user.Bot || i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	cfg := b.Config()
+	if b.ignored(context.Background(), i.GuildID, user.ID, cfg) {
 		return
 	}
 
+	guild := b.guildSettings(i.GuildID)
+
+	// Replies to interactions fall back to the interaction's locale before the guild's.
+	ctx := i18n.NewContext(context.Background(), b.localizer(guild, user.ID, string(i.Locale)))
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: i18n.FromContext(ctx).T("interaction.unsupported", guild.Prefix),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		logger := logging.WithComponent("discord").With("guild_id", i.GuildID)
+		logger.WarnContext(ctx, "Failed to respond to interaction", "error", err)
+	}
+}
+
+// dispatch parses a command message and runs its handler.
+func (b *Bot) dispatch(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, cfg *config.Config, guild *settings.Guild) {
+	_, dispatchSpan := tracing.Start(ctx, "discord.dispatch", tracing.SpanKindInternal)
+
 	// Remove prefix and split into command and args.
-	content := strings.TrimPrefix(m.Content, b.config.CommandPrefix)
+	content := strings.TrimPrefix(m.Content, guild.Prefix)
 
 	parts := strings.Fields(content)
 	if len(parts) == 0 {
+		dispatchSpan.End()
 		return
 	}
 
 	command := strings.ToLower(parts[0])
 	args := parts[1:]
 
-	// Handle specific commands.
-	if handler, exists := b.commandHandlers[command]; exists {
-		if err := handler(s, m, args); err != nil {
-			logger := logging.WithComponent("discord").With(
-				"user_id", m.Author.ID,
-				"username", m.Author.Username,
-				"command", command,
-			)
-			logging.LogError(logger, err, "Command execution failed")
-			metrics.RecordCommand(false)
-			metrics.RecordError(err)
-			b.sendErrorMessage(s, m.ChannelID, "Sorry, something went wrong processing your command.")
-		} else {
-			metrics.RecordCommand(true)
-			logging.LogDiscordCommand(m.Author.ID, m.Author.Username, command, true)
-		}
+	if b.rejectForMaintenance(ctx, s, m, cfg) {
+		dispatchSpan.SetAttributes(tracing.String("command", command), tracing.Bool("maintenance", true))
+		dispatchSpan.End()
 
 		return
 	}
 
+	handler, exists := b.commandHandlers[command]
+	dispatchSpan.SetAttributes(tracing.String("command", command), tracing.Bool("command.known", exists))
+	dispatchSpan.End()
+
 	// If no specific handler found, send unknown command message.
-	b.sendErrorMessage(s, m.ChannelID, fmt.Sprintf("Unknown command: %s%s. Use %shelp for available commands.", b.config.CommandPrefix, command, b.config.CommandPrefix))
+	if !exists {
+		b.sendErrorMessage(ctx, s, m.ChannelID, i18n.FromContext(ctx).T("error.unknown_command", guild.Prefix, command))
+		return
+	}
+
+	if blocked := b.blockedReason(ctx, m, guild, command); blocked != "" {
+		if !guild.SilentBlockedCommands {
+			b.sendErrorMessage(ctx, s, m.ChannelID, blocked)
+		}
+
+		return
+	}
+
+	if b.coolingDown(ctx, s, m, cfg) {
+		commandRejections.Inc(command, "cooldown")
+		return
+	}
+
+	handlerCtx, cancel := context.WithTimeout(ctx, cfg.RequestTimeout)
+	defer cancel()
+
+	handlerCtx, handlerSpan := tracing.Start(handlerCtx, "command "+command, tracing.SpanKindInternal,
+		tracing.String("command", command),
+		tracing.Int("command.args", len(args)),
+	)
+
+	start := time.Now()
+	err := handler(handlerCtx, s, m, args)
+	duration := time.Since(start)
+
+	// Rejected commands did not run; they are neither successful nor failed executions.
+	var rejected rejection
+	if stdErrors.As(err, &rejected) {
+		handlerSpan.SetAttributes(tracing.String("command.rejected", string(rejected)))
+		handlerSpan.End()
+		commandRejections.Inc(command, string(rejected))
+
+		return
+	}
+
+	handlerSpan.RecordError(err)
+	handlerSpan.End()
+
+	// Users who opted out are left out of the usage statistics and the command log.
+	if !b.privacy.OptedOut(m.Author.ID, privacy.Analytics) {
+		metrics.RecordCommandExecution(command, m.GuildID, err == nil, duration)
+	}
+
+	if err != nil {
+		logger := b.commandLogger(m, command)
+		logging.LogErrorContext(ctx, logger, err, "Command execution failed")
+		metrics.RecordError(err)
+		b.sendErrorMessage(ctx, s, m.ChannelID, i18n.FromContext(ctx).T("error.command_failed"))
+	} else if !b.privacy.OptedOut(m.Author.ID, privacy.MessageLogs) {
+		logging.LogDiscordCommand(m.Author.ID, m.Author.Username, command, true)
+	}
 }
 
 // handlePing handles the !ping command.
-func (b *Bot) handlePing(s *discordgo.Session, m *discordgo.MessageCreate, _ []string) error {
-	logger := logging.WithComponent("discord").With(
-		"user_id", m.Author.ID,
-		"username", m.Author.Username,
-		"command", "ping",
-	)
-	logger.Info("Handling ping command")
+func (b *Bot) handlePing(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, _ []string) error {
+	logger := b.commandLogger(m, "ping")
+	logger.InfoContext(ctx, "Handling ping command")
+
+	loc := i18n.FromContext(ctx)
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "Pong! 🏓",
-		Description: "Bot is online and responding!",
+		Title:       loc.T("ping.title"),
+		Description: loc.T("ping.measuring"),
 		Color:       0x00FF00, // Green color
 		Timestamp:   time.Now().Format(time.RFC3339),
 	}
 
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
+	// The time taken to send the reply is the REST round trip.
+	start := time.Now()
+
+	reply, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx))
 	if err != nil {
 		return errors.NewDiscordError("failed to send ping response", err)
 	}
 
+	restLatency := time.Since(start)
+
+	// Both timestamps are assigned by Discord, so their difference is the
+	// delay between the command message being created and the reply.
+	replyDelay := reply.Timestamp.Sub(m.Timestamp)
+
+	gatewayLatency := s.HeartbeatLatency()
+	gatewayStats := metrics.Get().GetGatewayLatencySummary()
+
+	embed.Description = loc.T("ping.online")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   loc.T("ping.gateway"),
+			Value:  formatLatency(loc, gatewayLatency),
+			Inline: true,
+		},
+		{
+			Name:   loc.T("ping.rest"),
+			Value:  formatLatency(loc, restLatency),
+			Inline: true,
+		},
+		{
+			Name:   loc.T("ping.reply"),
+			Value:  formatLatency(loc, replyDelay),
+			Inline: true,
+		},
+	}
+
+	if gatewayStats.Samples > 0 {
+		samples := int64(gatewayStats.Samples)
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: loc.N("ping.footer", samples, formatLatency(loc, gatewayStats.P50), formatLatency(loc, gatewayStats.P95),
+				formatLatency(loc, gatewayStats.P99), loc.Int(samples)),
+		}
+	}
+
+	if _, err := s.ChannelMessageEditEmbed(m.ChannelID, reply.ID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to update ping response", err)
+	}
+
 	return nil
 }
 
-// sendErrorMessage sends an error message to a Discord channel.
-func (b *Bot) sendErrorMessage(s *discordgo.Session, channelID, message string) {
+// sendErrorMessage sends an error message to a Discord channel, titled in the language
+// carried by ctx.
+func (b *Bot) sendErrorMessage(ctx context.Context, s *discordgo.Session, channelID, message string) {
 	embed := &discordgo.MessageEmbed{
-		Title:       "Error",
+		Title:       i18n.FromContext(ctx).T("error.title"),
 		Description: message,
 		Color:       0xE74C3C, // Red color.
 	}
 
-	if _, err := s.ChannelMessageSendEmbed(channelID, embed); err != nil {
+	if _, err := s.ChannelMessageSendEmbed(channelID, embed, discordgo.WithContext(ctx)); err != nil {
 		logger := logging.WithComponent("discord")
-		logger.Error("Failed to send error message", "error", err)
+		logger.ErrorContext(ctx, "Failed to send error message", "error", err)
 	}
 }
 
 // handleHelp handles the !help command.
-func (b *Bot) handleHelp(s *discordgo.Session, m *discordgo.MessageCreate, _ []string) error {
-	logger := logging.WithComponent("discord").With(
-		"user_id", m.Author.ID,
-		"username", m.Author.Username,
-		"command", "help",
-	)
-	logger.Info("Showing help information")
+func (b *Bot) handleHelp(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, _ []string) error {
+	logger := b.commandLogger(m, "help")
+	logger.InfoContext(ctx, "Showing help information")
+
+	prefix := b.prefix(m.GuildID)
+	loc := i18n.FromContext(ctx)
 
 	embed := &discordgo.MessageEmbed{
-		Title:       "Discord Bot Help",
-		Description: "A generic Discord bot template built with Go!",
+		Title:       loc.T("help.title"),
+		Description: loc.T("help.description"),
 		Color:       0x3498DB, // Blue color.
 		Fields: []*discordgo.MessageEmbedField{
 			{
-				Name:   fmt.Sprintf("%sping", b.config.CommandPrefix),
-				Value:  "Check if the bot is online and responding",
+				Name:   fmt.Sprintf("%sping", prefix),
+				Value:  loc.T("help.ping"),
 				Inline: false,
 			},
 			{
-				Name:   fmt.Sprintf("%shelp", b.config.CommandPrefix),
-				Value:  "Show this help message",
+				Name:   fmt.Sprintf("%shelp", prefix),
+				Value:  loc.T("help.help"),
 				Inline: false,
 			},
 			{
-				Name:   fmt.Sprintf("%sstats", b.config.CommandPrefix),
-				Value:  "Show bot performance statistics",
+				Name:   fmt.Sprintf("%sstats", prefix),
+				Value:  loc.T("help.stats", prefix),
 				Inline: false,
 			},
-		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "🚀 Built with Go, DiscordGo, and Mage - Ready for customization!",
-		},
-	}
-
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	if err != nil {
-		return errors.NewDiscordError("failed to send help message", err)
-	}
-
-	return nil
-}
-
-// handleStats handles the !stats command.
-func (b *Bot) handleStats(s *discordgo.Session, m *discordgo.MessageCreate, _ []string) error {
-	logger := logging.WithComponent("discord").With(
-		"user_id", m.Author.ID,
-		"username", m.Author.Username,
-		"command", "stats",
-	)
-	logger.Info("Showing bot statistics")
-
-	summary := metrics.Get().GetSummary()
-	uptime := time.Duration(summary.UptimeSeconds * float64(time.Second))
-
-	// Format uptime nicely.
-	uptimeStr := formatDuration(uptime)
-
-	embed := &discordgo.MessageEmbed{
-		Title: "Bot Statistics",
-		Color: 0x2ECC71, // Green color.
-		Fields: []*discordgo.MessageEmbedField{
 			{
-				Name: "📊 Commands",
-				Value: fmt.Sprintf("Total: %d\nSuccessful: %d\nFailed: %d\nSuccess Rate: %.1f%%",
-					summary.CommandsTotal, summary.CommandsSuccessful, summary.CommandsFailed, summary.CommandSuccessRate),
-				Inline: true,
+				Name:   fmt.Sprintf("%slanguage", prefix),
+				Value:  loc.T("help.language", prefix, languagesDisplay()),
+				Inline: false,
+			},
+			{
+				Name:   fmt.Sprintf("%ssettings", prefix),
+				Value:  loc.T("help.settings", prefix),
+				Inline: false,
 			},
 			{
-				Name: "🌐 API Requests",
-				Value: fmt.Sprintf("Total: %d\nSuccess Rate: %.1f%%\nAvg Response: %.0fms",
-					summary.APIRequestsTotal, summary.APISuccessRate, summary.AverageResponseTime),
-				Inline: true,
+				Name:   fmt.Sprintf("%sprivacy", prefix),
+				Value:  loc.T("help.privacy", prefix),
+				Inline: false,
 			},
 			{
-				Name: "⚡ Performance",
-				Value: fmt.Sprintf("Commands/sec: %.2f\nAPI Requests/sec: %.2f",
-					summary.CommandsPerSecond, summary.APIRequestsPerSecond),
-				Inline: true,
+				Name:   fmt.Sprintf("%srules", prefix),
+				Value:  loc.T("help.rules", prefix),
+				Inline: false,
 			},
 			{
-				Name:   "⏱️ Uptime",
-				Value:  uptimeStr,
-				Inline: true,
+				Name:   fmt.Sprintf("%sblock", prefix),
+				Value:  loc.T("help.block", prefix),
+				Inline: false,
 			},
 			{
-				Name:   "🚀 Started",
-				Value:  fmt.Sprintf("<t:%d:R>", time.Now().Add(-uptime).Unix()),
-				Inline: true,
+				Name:   fmt.Sprintf("%[1]sexport / %[1]simport", prefix),
+				Value:  loc.T("help.archive", prefix),
+				Inline: false,
 			},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Statistics since bot startup",
+			Text: loc.T("help.footer"),
 		},
 	}
 
-	// Add error information if there are errors.
-	if len(summary.ErrorsByType) > 0 {
-		errorInfo := make([]string, 0, len(summary.ErrorsByType))
-		for errorType, count := range summary.ErrorsByType {
-			if count > 0 {
-				errorInfo = append(errorInfo, fmt.Sprintf("%s: %d", string(errorType), count))
-			}
-		}
-
-		if len(errorInfo) > 0 {
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-				Name:   "⚠️ Errors",
-				Value:  strings.Join(errorInfo, "\n"),
-				Inline: false,
-			})
-		}
-	}
-
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
+	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx))
 	if err != nil {
-		return errors.NewDiscordError("failed to send stats message", err)
+		return errors.NewDiscordError("failed to send help message", err)
 	}
 
 	return nil
 }
-
-// formatDuration formats a duration into a human-readable string.
-func formatDuration(d time.Duration) string {
-	days := int(d.Hours()) / 24
-	hours := int(d.Hours()) % 24
-	minutes := int(d.Minutes()) % 60
-	seconds := int(d.Seconds()) % 60
-
-	switch {
-	case days > 0:
-		return fmt.Sprintf("%dd %dh %dm %ds", days, hours, minutes, seconds)
-	case hours > 0:
-		return fmt.Sprintf("%dh %dm %ds", hours, minutes, seconds)
-	case minutes > 0:
-		return fmt.Sprintf("%dm %ds", minutes, seconds)
-	default:
-		return fmt.Sprintf("%ds", seconds)
-	}
-}
@@ -0,0 +1,207 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+)
+
+// settingsPerPage is the number of settings on each page of !config show. Discord allows
+// at most 25 fields per embed; a multiple of three keeps the inline columns aligned.
+const settingsPerPage = 15
+
+// intentNames names the gateway intents, with the privileged ones marked.
+var intentNames = []struct {
+	intent discordgo.Intent
+	name   string
+}{
+	{discordgo.IntentGuilds, "Guilds"},
+	{discordgo.IntentGuildMembers, "Guild Members (privileged)"},
+	{discordgo.IntentGuildBans, "Guild Bans"},
+	{discordgo.IntentGuildEmojis, "Guild Emojis"},
+	{discordgo.IntentGuildIntegrations, "Guild Integrations"},
+	{discordgo.IntentGuildWebhooks, "Guild Webhooks"},
+	{discordgo.IntentGuildInvites, "Guild Invites"},
+	{discordgo.IntentGuildVoiceStates, "Guild Voice States"},
+	{discordgo.IntentGuildPresences, "Guild Presences (privileged)"},
+	{discordgo.IntentGuildMessages, "Guild Messages"},
+	{discordgo.IntentGuildMessageReactions, "Guild Message Reactions"},
+	{discordgo.IntentGuildMessageTyping, "Guild Message Typing"},
+	{discordgo.IntentDirectMessages, "Direct Messages"},
+	{discordgo.IntentDirectMessageReactions, "Direct Message Reactions"},
+	{discordgo.IntentDirectMessageTyping, "Direct Message Typing"},
+	{discordgo.IntentMessageContent, "Message Content (privileged)"},
+	{discordgo.IntentGuildScheduledEvents, "Guild Scheduled Events"},
+	{discordgo.IntentAutoModerationConfiguration, "Auto Moderation Configuration"},
+	{discordgo.IntentAutoModerationExecution, "Auto Moderation Execution"},
+}
+
+// handleConfig handles the owner-only !config command.
+func (b *Bot) handleConfig(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	cfg := b.Config()
+	loc := i18n.FromContext(ctx)
+
+	if len(args) == 0 || !strings.EqualFold(args[0], "show") {
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("config.usage", b.prefix(m.GuildID)))
+		return nil
+	}
+
+	pages := configPageCount()
+
+	page := 1
+	if len(args) > 1 {
+		var err error
+		if page, err = strconv.Atoi(args[1]); err != nil || page < 1 || page > pages {
+			b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("config.page_range", pages))
+			return nil
+		}
+	}
+
+	logger := b.commandLogger(m, "config")
+	logger.InfoContext(ctx, "Showing configuration", "page", page)
+
+	var embed *discordgo.MessageEmbed
+	if page == 1 {
+		embed = b.configOverviewEmbed(loc, cfg)
+	} else {
+		embed = configSettingsEmbed(loc, cfg, page)
+	}
+
+	footer := loc.T("config.page", page, pages)
+	if page < pages {
+		footer = loc.T("config.page_next", page, pages, b.prefix(m.GuildID), page+1)
+	}
+
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: footer}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send configuration", err)
+	}
+
+	return nil
+}
+
+// configPageCount returns the number of pages of !config show: the overview and the settings.
+func configPageCount() int {
+	return 1 + (len(config.Fields())+settingsPerPage-1)/settingsPerPage
+}
+
+// configOverviewEmbed builds the first page of !config show, with the settings derived
+// from the configuration and the session rather than set directly.
+func (b *Bot) configOverviewEmbed(loc *i18n.Localizer, cfg *config.Config) *discordgo.MessageEmbed {
+	var intents []string
+
+	for _, entry := range intentNames {
+		if b.session.Identify.Intents&entry.intent != 0 {
+			intents = append(intents, entry.name)
+		}
+	}
+
+	if len(intents) == 0 {
+		intents = append(intents, loc.T("config.none"))
+	}
+
+	features := []string{
+		loc.T("config.metrics", enabledIf(loc, cfg.MetricsAddr != "", cfg.MetricsAddr)),
+		loc.T("config.per_guild_metrics", enabledIf(loc, cfg.MetricsPerGuild, "")),
+		loc.T("config.metrics_persistence", enabledIf(loc, cfg.MetricsPersist, cfg.MetricsStateFile)),
+		loc.T("config.statsd", enabledIf(loc, cfg.StatsDAddr != "", cfg.StatsDAddr)),
+		loc.T("config.tracing", enabledIf(loc, cfg.TracingEnabled, loc.T("config.sampled", loc.Percent(cfg.TracingSampleRatio*100)))),
+		loc.T("config.alerting", enabledIf(loc, cfg.AlertsEnabled, alertTarget(loc, cfg))),
+		loc.T("config.debug", enabledIf(loc, cfg.DebugMode, "")),
+		loc.T("config.storage", storageDescription(cfg)),
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       loc.T("config.title"),
+		Description: loc.T("config.description"),
+		Color:       0x3498DB, // Blue color.
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   loc.T("config.intents"),
+				Value:  truncateField(fmt.Sprintf("%s\n(`%d`)", strings.Join(intents, "\n"), b.session.Identify.Intents)),
+				Inline: true,
+			},
+			{
+				Name:   loc.T("config.features"),
+				Value:  truncateField(strings.Join(features, "\n")),
+				Inline: true,
+			},
+			{
+				Name:   loc.T("config.owners"),
+				Value:  loc.N("config.owners.value", int64(len(cfg.OwnerIDs)), loc.Int(int64(len(cfg.OwnerIDs)))),
+				Inline: false,
+			},
+		},
+	}
+}
+
+// configSettingsEmbed builds a page of settings of !config show, each with its value and source.
+func configSettingsEmbed(loc *i18n.Localizer, cfg *config.Config, page int) *discordgo.MessageEmbed {
+	fields := config.Fields()
+
+	start := (page - 2) * settingsPerPage
+	end := min(start+settingsPerPage, len(fields))
+
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("config.settings_title"),
+		Description: loc.T("config.settings_description"),
+		Color:       0x3498DB, // Blue color.
+	}
+
+	for _, field := range fields[start:end] {
+		value := loc.T("config.empty")
+		if v := field.Value(cfg); v != "" {
+			value = "`" + v + "`"
+		}
+
+		name := field.Key
+		if field.RequiresRestart {
+			name += " ⏳"
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   name,
+			Value:  truncateField(fmt.Sprintf("%s\n%s", value, cfg.Source(field.Key))),
+			Inline: true,
+		})
+	}
+
+	return embed
+}
+
+// enabledIf describes whether a feature is enabled, with detail when it is.
+func enabledIf(loc *i18n.Localizer, enabled bool, detail string) string {
+	switch {
+	case !enabled:
+		return loc.T("config.off")
+	case detail == "":
+		return loc.T("config.on")
+	default:
+		return loc.T("config.on_detail", detail)
+	}
+}
+
+// alertTarget describes where alerts are delivered.
+func alertTarget(loc *i18n.Localizer, cfg *config.Config) string {
+	if cfg.AlertChannelID != "" {
+		return "<#" + cfg.AlertChannelID + ">"
+	}
+
+	return loc.T("config.owner_dms")
+}
+
+// storageDescription describes the storage backend.
+func storageDescription(cfg *config.Config) string {
+	if cfg.StorageBackend == "bolt" {
+		return "bolt (" + cfg.StoragePath + ")"
+	}
+
+	return cfg.StorageBackend
+}
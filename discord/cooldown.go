@@ -0,0 +1,92 @@
+package discord
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/i18n"
+)
+
+// cooldownSweepSize is the number of tracked users at which cooldowns first drops the users
+// whose cooldown ended.
+const cooldownSweepSize = 1024
+
+// cooldowns tracks when users may run their next command. It is safe for concurrent use.
+type cooldowns struct {
+	mutex sync.Mutex
+	users map[string]cooldown
+	// sweepAt is the number of tracked users at which the next sweep happens.
+	sweepAt int
+}
+
+// cooldown is the cooldown of one user.
+type cooldown struct {
+	until time.Time
+	// warned is set once the user was told to wait, so further hits get no reply.
+	warned bool
+}
+
+func newCooldowns() *cooldowns {
+	return &cooldowns{users: make(map[string]cooldown), sweepAt: cooldownSweepSize}
+}
+
+// hit records a command of a user. Outside of a cooldown, it starts one of length period and
+// returns 0; within one, it returns the time left and whether this is the first hit.
+func (c *cooldowns) hit(userID string, period time.Duration, now time.Time) (time.Duration, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if current, ok := c.users[userID]; ok && now.Before(current.until) {
+		first := !current.warned
+		current.warned = true
+		c.users[userID] = current
+
+		return current.until.Sub(now), first
+	}
+
+	// Like Detector, sweep only when the map doubled since the last sweep.
+	if len(c.users) >= c.sweepAt {
+		for id, current := range c.users {
+			if !now.Before(current.until) {
+				delete(c.users, id)
+			}
+		}
+
+		c.sweepAt = max(cooldownSweepSize, 2*len(c.users))
+	}
+
+	c.users[userID] = cooldown{until: now.Add(period)}
+
+	return 0, false
+}
+
+// coolingDown reports whether the author of a command message ran another command less than
+// ABUSE_COMMAND_COOLDOWN ago. Such commands are rejected and count as strikes; the author is
+// told once per cooldown how long to wait. Owners have no cooldown.
+func (b *Bot) coolingDown(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, cfg *config.Config) bool {
+	if cfg.AbuseCommandCooldown <= 0 || cfg.IsOwner(m.Author.ID) {
+		return false
+	}
+
+	remaining, first := b.cooldowns.hit(m.Author.ID, cfg.AbuseCommandCooldown, time.Now())
+	if remaining == 0 {
+		return false
+	}
+
+	b.authorLogger(m).DebugContext(ctx, "Rejected command during cooldown", "remaining", remaining)
+
+	if first {
+		// Round up, so the reply never asks to wait 0 seconds.
+		wait := (remaining + time.Second - 1).Truncate(time.Second)
+
+		loc := i18n.FromContext(ctx)
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("error.cooldown", loc.Duration(wait)))
+	}
+
+	b.strike(ctx, s, m, "cooldown")
+
+	return true
+}
@@ -0,0 +1,109 @@
+package discord
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/logging"
+	"github.com/dunamismax/discogo/settings"
+)
+
+// localizer returns the localizer for a user's replies. The language is, in order of
+// preference: the one the user chose with !language, the locale of the interaction when
+// the command came from one, and the guild's locale setting. interactionLocale is empty
+// for message commands; interaction handlers pass the Locale of the interaction.
+func (b *Bot) localizer(guild *settings.Guild, userID, interactionLocale string) *i18n.Localizer {
+	chosen, err := b.locales.Locale(userID)
+	if err != nil {
+		logger := logging.WithComponent("discord").With("user_id", userID)
+		logging.LogError(logger, err, "Failed to load the user's language, using the server's")
+	}
+
+	return i18n.Resolve(chosen, interactionLocale, guild.Locale)
+}
+
+// handleLanguage handles the !language command, with which users choose the language of
+// the bot's replies to them, in every server.
+func (b *Bot) handleLanguage(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	loc := i18n.FromContext(ctx)
+	prefix := b.prefix(m.GuildID)
+	available := languagesDisplay()
+
+	var description string
+
+	switch {
+	case len(args) == 0:
+		chosen, err := b.locales.Locale(m.Author.ID)
+		if err != nil {
+			return err
+		}
+
+		current := loc.T("language.current_default", languageName(loc))
+		if chosen != "" {
+			current = loc.T("language.current", languageName(loc))
+		}
+
+		description = current + "\n" + loc.T("language.available", available, prefix)
+	case len(args) == 1 && strings.EqualFold(args[0], "reset"):
+		if err := b.locales.SetLocale(m.Author.ID, ""); err != nil {
+			return err
+		}
+
+		// Answer in the language the user gets from now on.
+		loc = b.localizer(b.guildSettings(m.GuildID), m.Author.ID, "")
+		description = loc.T("language.reset")
+	case len(args) == 1:
+		locale, ok := i18n.Match(args[0])
+		if !ok {
+			b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("language.unknown", args[0], available))
+			return nil
+		}
+
+		if err := b.locales.SetLocale(m.Author.ID, locale); err != nil {
+			return err
+		}
+
+		loc = i18n.New(locale)
+		description = loc.T("language.set", languageName(loc))
+	default:
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("language.usage", prefix))
+		return nil
+	}
+
+	if len(args) == 1 {
+		logger := b.commandLogger(m, "language")
+		logger.InfoContext(ctx, "Language changed", "locale", strings.ToLower(args[0]))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("language.title"),
+		Description: description,
+		Color:       0x3498DB, // Blue color.
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send language", err)
+	}
+
+	return nil
+}
+
+// languageName returns the name and locale of a localizer's language.
+func languageName(loc *i18n.Localizer) string {
+	return i18n.Language{Locale: loc.Locale(), Name: loc.T("language.name")}.String()
+}
+
+// languagesDisplay lists the available languages.
+func languagesDisplay() string {
+	languages := i18n.Languages()
+	names := make([]string, 0, len(languages))
+
+	for _, language := range languages {
+		names = append(names, language.String())
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,183 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/logging"
+	"github.com/dunamismax/discogo/metrics"
+)
+
+var maintenanceRejections = metrics.RegisterCounter("maintenance_rejected_commands_total",
+	"Commands rejected because the bot is in maintenance mode.")
+
+// Maintenance describes the maintenance mode of the bot, during which only owners can use
+// commands.
+type Maintenance struct {
+	// Message explains the maintenance to users; optional.
+	Message string
+	// ETA is when the maintenance is expected to end; zero when unknown.
+	ETA time.Time
+	// Since is when maintenance mode was turned on.
+	Since time.Time
+	// Source tells what turned maintenance mode on: "configuration" or "command", an
+	// owner's !maintenance.
+	Source string
+	// By is the username of the owner who turned maintenance mode on; empty for the
+	// configuration.
+	By string
+}
+
+// Maintenance returns the current maintenance mode, or nil when the bot is not in it.
+func (b *Bot) Maintenance() *Maintenance {
+	return b.maintenance.Load()
+}
+
+// SetMaintenance turns maintenance mode on, or off when maintenance is nil, and updates the
+// bot's presence.
+func (b *Bot) SetMaintenance(maintenance *Maintenance) {
+	b.maintenance.Store(maintenance)
+
+	logger := logging.WithComponent("discord")
+	if maintenance != nil {
+		logger.Warn("Maintenance mode on", "source", maintenance.Source, "by", maintenance.By, "eta", maintenance.ETA,
+			"message", maintenance.Message)
+	} else {
+		logger.Info("Maintenance mode off")
+	}
+
+	b.updatePresence()
+}
+
+// applyMaintenanceConfig follows the maintenance settings of a new configuration when they
+// changed, so a reload turns maintenance mode on or off; an owner's !maintenance stays in
+// effect until then. Only a reload that turns MAINTENANCE_MODE off ends maintenance; one
+// that changes the message or ETA while it is off leaves an owner's !maintenance alone.
+func (b *Bot) applyMaintenanceConfig(previous, cfg *config.Config) {
+	if previous != nil && previous.MaintenanceMode == cfg.MaintenanceMode &&
+		previous.MaintenanceMessage == cfg.MaintenanceMessage && previous.MaintenanceETA == cfg.MaintenanceETA {
+		return
+	}
+
+	if !cfg.MaintenanceMode {
+		if previous != nil && previous.MaintenanceMode && b.Maintenance() != nil {
+			b.SetMaintenance(nil)
+		}
+
+		return
+	}
+
+	// Validate has already checked the ETA.
+	eta, _ := time.Parse(time.RFC3339, cfg.MaintenanceETA)
+
+	b.SetMaintenance(&Maintenance{
+		Message: cfg.MaintenanceMessage,
+		ETA:     eta,
+		Since:   time.Now(),
+		Source:  "configuration",
+	})
+}
+
+// rejectForMaintenance answers a command with the maintenance notice when the bot is in
+// maintenance mode and the author is not an owner, and reports whether it did.
+func (b *Bot) rejectForMaintenance(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, cfg *config.Config) bool {
+	maintenance := b.Maintenance()
+	if maintenance == nil || cfg.IsOwner(m.Author.ID) {
+		return false
+	}
+
+	maintenanceRejections.Inc()
+
+	embed := maintenanceEmbed(i18n.FromContext(ctx), maintenance)
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		logger := logging.WithComponent("discord")
+		logger.ErrorContext(ctx, "Failed to send maintenance notice", "error", err)
+	}
+
+	return true
+}
+
+// handleMaintenance handles the owner-only !maintenance command, which shows or toggles
+// maintenance mode.
+func (b *Bot) handleMaintenance(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	loc := i18n.FromContext(ctx)
+
+	var subcommand string
+	if len(args) > 0 {
+		subcommand = strings.ToLower(args[0])
+	}
+
+	switch subcommand {
+	case "":
+	case "on":
+		maintenance := &Maintenance{Since: time.Now(), Source: "command", By: m.Author.Username}
+		args = args[1:]
+
+		if len(args) > 0 {
+			if duration, err := time.ParseDuration(args[0]); err == nil && duration > 0 {
+				maintenance.ETA = maintenance.Since.Add(duration)
+				args = args[1:]
+			}
+		}
+
+		maintenance.Message = strings.Join(args, " ")
+		b.SetMaintenance(maintenance)
+	case "off":
+		b.SetMaintenance(nil)
+	default:
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("maintenance.usage", b.prefix(m.GuildID)))
+		return nil
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("maintenance.off_title"),
+		Description: loc.T("maintenance.off"),
+		Color:       0x2ECC71, // Green color.
+	}
+
+	if maintenance := b.Maintenance(); maintenance != nil {
+		footer := loc.T("maintenance.on_by_config")
+		if maintenance.By != "" {
+			footer = loc.T("maintenance.on_by", maintenance.By)
+		}
+
+		embed = maintenanceEmbed(loc, maintenance)
+		embed.Title = loc.T("maintenance.on_title")
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: footer}
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send maintenance status", err)
+	}
+
+	return nil
+}
+
+// maintenanceEmbed is the notice sent to users during maintenance.
+func maintenanceEmbed(loc *i18n.Localizer, maintenance *Maintenance) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("maintenance.title"),
+		Description: loc.T("maintenance.description"),
+		Color:       0xF39C12, // Orange color.
+		Timestamp:   maintenance.Since.Format(time.RFC3339),
+	}
+
+	if maintenance.Message != "" {
+		embed.Description = maintenance.Message
+	}
+
+	if !maintenance.ETA.IsZero() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  loc.T("maintenance.eta"),
+			Value: fmt.Sprintf("<t:%[1]d:R> (<t:%[1]d:t>)", maintenance.ETA.Unix()),
+		})
+	}
+
+	return embed
+}
@@ -0,0 +1,112 @@
+package discord
+
+import (
+	"context"
+	"regexp"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/metrics"
+	"github.com/dunamismax/discogo/settings"
+)
+
+// inviteCode matches Discord invite links and captures their code.
+var inviteCode = regexp.MustCompile(`(?i)(?:discord(?:app)?\.com/invite|discord\.gg)/([a-z0-9-]+)`)
+
+var moderationDeletions = metrics.RegisterCounter("moderation_deleted_messages_total",
+	"Messages deleted by the moderation filters.", "filter")
+
+// moderate applies the moderation filters of a guild to a message: it deletes messages
+// with invites to other servers when moderation.filter_invites is on, and messages that
+// mention more members than moderation.max_mentions. Owners, administrators and members
+// with moderation.mod_role are exempt. It reports whether it deleted the message.
+func (b *Bot) moderate(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, guild *settings.Guild, cfg *config.Config) bool {
+	moderation := guild.Moderation
+	if m.GuildID == "" {
+		return false
+	}
+
+	mentions := moderation.MaxMentions > 0 && len(m.Mentions) > moderation.MaxMentions
+	invites := moderation.FilterInvites && inviteCode.MatchString(m.Content)
+
+	if !mentions && !invites {
+		return false
+	}
+
+	logger := b.authorLogger(m).With("guild_id", m.GuildID)
+
+	administrator, err := b.administrator(ctx, s, m, cfg)
+	if err != nil {
+		// Rather keep a message than delete one of an administrator.
+		logger.WarnContext(ctx, "Failed to check whether the author is exempt from moderation", "error", err)
+		return false
+	}
+
+	if administrator || hasModRole(m, guild) {
+		return false
+	}
+
+	loc := i18n.FromContext(ctx)
+	filter, reason := "mentions", loc.T("moderation.mentions", m.Author.ID, moderation.MaxMentions)
+
+	if !mentions {
+		// Resolving invites takes a request each, so it is left for last.
+		if !b.hasForeignInvite(ctx, s, m) {
+			return false
+		}
+
+		filter, reason = "invites", loc.T("moderation.invites", m.Author.ID)
+	}
+
+	logger = logger.With("filter", filter)
+
+	if err := s.ChannelMessageDelete(m.ChannelID, m.ID, discordgo.WithContext(ctx)); err != nil {
+		logger.WarnContext(ctx, "Failed to delete message, the bot may lack the Manage Messages permission", "error", err)
+		return false
+	}
+
+	moderationDeletions.Inc(filter)
+	logger.InfoContext(ctx, "Deleted message")
+
+	b.sendErrorMessage(ctx, s, m.ChannelID, reason)
+
+	return true
+}
+
+// hasForeignInvite reports whether a message links an invite to another server. Invites
+// that cannot be resolved count as foreign.
+func (b *Bot) hasForeignInvite(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate) bool {
+	for _, match := range inviteCode.FindAllStringSubmatch(m.Content, -1) {
+		invite, err := s.Invite(match[1], discordgo.WithContext(ctx))
+		if err != nil || invite.Guild == nil || invite.Guild.ID != m.GuildID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// administrator reports whether the author of a guild message is an owner or a member with
+// the Manage Server permission.
+func (b *Bot) administrator(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, cfg *config.Config) (bool, error) {
+	if cfg.IsOwner(m.Author.ID) {
+		return true, nil
+	}
+
+	permissions, err := s.UserChannelPermissions(m.Author.ID, m.ChannelID, discordgo.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+
+	// Administrators have every permission.
+	return permissions&discordgo.PermissionManageServer != 0, nil
+}
+
+// hasModRole reports whether the author of a guild message has the guild's moderator role.
+func hasModRole(m *discordgo.MessageCreate, guild *settings.Guild) bool {
+	role := guild.Moderation.ModRoleID
+
+	return role != "" && m.Member != nil && slices.Contains(m.Member.Roles, role)
+}
@@ -0,0 +1,159 @@
+package discord
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/logging"
+	"github.com/dunamismax/discogo/metrics"
+	"github.com/dunamismax/discogo/presence"
+)
+
+// activityTypes maps status kinds to Discord activity types.
+var activityTypes = map[presence.Kind]discordgo.ActivityType{
+	presence.Playing:   discordgo.ActivityTypeGame,
+	presence.Watching:  discordgo.ActivityTypeWatching,
+	presence.Listening: discordgo.ActivityTypeListening,
+	presence.Competing: discordgo.ActivityTypeCompeting,
+	presence.Custom:    discordgo.ActivityTypeCustom,
+}
+
+// onReady sets the presence again after every new gateway session.
+func (b *Bot) onReady(_ *discordgo.Session, _ *discordgo.Ready) {
+	b.updatePresence()
+}
+
+// rotatePresence moves to the next configured status every PRESENCE_INTERVAL until the bot
+// stops. Statuses are rendered again on every tick, so their values stay current.
+func (b *Bot) rotatePresence() {
+	defer b.wg.Done()
+
+	timer := time.NewTimer(b.Config().PresenceInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-timer.C:
+			b.presenceIndex.Add(1)
+			b.updatePresence()
+			timer.Reset(b.Config().PresenceInterval)
+		}
+	}
+}
+
+// SetAlertFiring records whether an alert rule is firing. The bot shows a degraded presence
+// while any rule is, instead of its configured statuses. It is meant as the alert manager's
+// OnStateChange, so the presence follows the rules even while notifications are held back.
+func (b *Bot) SetAlertFiring(rule string, firing bool) {
+	b.alertsMutex.Lock()
+	wasDegraded := len(b.firingAlerts) > 0
+
+	if firing {
+		b.firingAlerts[rule] = true
+	} else {
+		delete(b.firingAlerts, rule)
+	}
+
+	changed := wasDegraded != (len(b.firingAlerts) > 0)
+	b.alertsMutex.Unlock()
+
+	if changed {
+		b.updatePresence()
+	}
+}
+
+// degraded reports whether an alert rule is firing.
+func (b *Bot) degraded() bool {
+	b.alertsMutex.Lock()
+	defer b.alertsMutex.Unlock()
+
+	return len(b.firingAlerts) > 0
+}
+
+// updatePresence sets the bot's presence: maintenance mode first, then a degraded state,
+// then the current configured status.
+func (b *Bot) updatePresence() {
+	// The gateway is not connected before Start; onReady sets the presence then.
+	if err := b.session.UpdateStatusComplex(b.presenceStatus()); err != nil && err != discordgo.ErrWSNotFound {
+		logger := logging.WithComponent("discord")
+		logger.Warn("Failed to update presence", "error", err)
+	}
+}
+
+// presenceStatus returns the presence the bot should show now. Everyone sees the same
+// presence, so its texts are in the default language.
+func (b *Bot) presenceStatus() discordgo.UpdateStatusData {
+	loc := i18n.Default()
+
+	switch {
+	case b.Maintenance() != nil:
+		return discordgo.UpdateStatusData{
+			Status:     string(discordgo.StatusDoNotDisturb),
+			Activities: []*discordgo.Activity{customActivity(loc.T("presence.maintenance"))},
+		}
+	case b.degraded():
+		return discordgo.UpdateStatusData{
+			Status:     string(discordgo.StatusIdle),
+			Activities: []*discordgo.Activity{customActivity(loc.T("presence.degraded"))},
+		}
+	}
+
+	status := discordgo.UpdateStatusData{Status: string(discordgo.StatusOnline), Activities: []*discordgo.Activity{}}
+
+	cfg := b.Config()
+	if len(cfg.PresenceStatuses) == 0 {
+		return status
+	}
+
+	spec := cfg.PresenceStatuses[b.presenceIndex.Load()%uint64(len(cfg.PresenceStatuses))]
+	logger := logging.WithComponent("discord").With("status", spec)
+
+	parsed, err := presence.Parse(spec)
+	if err != nil {
+		// Validate has already checked the statuses.
+		logger.Warn("Invalid presence status", "error", err)
+		return status
+	}
+
+	text, err := parsed.Render(b.presenceData())
+	if err != nil {
+		logging.LogError(logger, err, "Failed to render presence status")
+		return status
+	}
+
+	activity := &discordgo.Activity{Name: text, Type: activityTypes[parsed.Kind]}
+	if parsed.Kind == presence.Custom {
+		activity = customActivity(text)
+	}
+
+	status.Activities = []*discordgo.Activity{activity}
+
+	return status
+}
+
+// presenceData collects the values statuses are filled with.
+func (b *Bot) presenceData() presence.Data {
+	cfg := b.Config()
+	summary := metrics.Get().GetSummary()
+
+	b.session.State.RLock()
+	guilds := len(b.session.State.Guilds)
+	b.session.State.RUnlock()
+
+	return presence.Data{
+		BotName:  cfg.BotName,
+		Prefix:   cfg.CommandPrefix,
+		Guilds:   guilds,
+		Commands: summary.Lifetime.CommandsTotal,
+		Uptime:   i18n.Default().Duration(time.Duration(summary.UptimeSeconds * float64(time.Second))),
+		Summary:  summary,
+	}
+}
+
+// customActivity returns a custom status with the given text.
+func customActivity(text string) *discordgo.Activity {
+	return &discordgo.Activity{Name: text, Type: discordgo.ActivityTypeCustom, State: text}
+}
@@ -0,0 +1,268 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/archive"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/logging"
+	"github.com/dunamismax/discogo/privacy"
+)
+
+// privacyRecord is a record about a user in the file sent by !privacy view.
+type privacyRecord struct {
+	Namespace string        `json:"namespace"`
+	Key       string        `json:"key"`
+	Value     archive.Value `json:"value"`
+}
+
+// handlePrivacy handles the !privacy command, with which users see the data the bot stores
+// about them, opt out of tracking and have their data deleted.
+func (b *Bot) handlePrivacy(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	loc := i18n.FromContext(ctx)
+	prefix := b.prefix(m.GuildID)
+
+	var subcommand string
+	if len(args) > 0 {
+		subcommand = strings.ToLower(args[0])
+	}
+
+	switch {
+	case subcommand == "" || (subcommand == "view" && len(args) == 1):
+		return b.sendPrivacyRecords(ctx, s, m)
+	case (subcommand == "opt-out" || subcommand == "opt-in") && len(args) == 2:
+		return b.setPrivacyOptOut(ctx, s, m, args[1], subcommand == "opt-out")
+	case subcommand == "delete" && len(args) == 1:
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("privacy.delete_confirm", prefix))
+		return nil
+	case subcommand == "delete" && len(args) == 2 && strings.EqualFold(args[1], "confirm"):
+		return b.deletePrivacyRecords(ctx, s, m)
+	default:
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("privacy.usage", prefix))
+		return nil
+	}
+}
+
+// sendPrivacyRecords sends a user their privacy choices and every record stored about them,
+// in a direct message.
+func (b *Bot) sendPrivacyRecords(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate) error {
+	records, err := b.privacy.Records(m.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	optOuts, err := b.privacy.OptOuts(m.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	privacyLogger(m, "view").InfoContext(ctx, "User viewed their data", "records", len(records))
+
+	loc := i18n.FromContext(ctx)
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("privacy.title"),
+		Description: loc.N("privacy.records", int64(len(records)), loc.Int(int64(len(records)))),
+		Color:       0x3498DB, // Blue color.
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:  loc.T("privacy.tracking"),
+				Value: trackingDisplay(loc, optOuts),
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: loc.T("privacy.footer"),
+		},
+	}
+
+	message := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+
+	if len(records) == 0 {
+		embed.Description = loc.T("privacy.no_records")
+	} else {
+		file := make([]privacyRecord, 0, len(records))
+		for _, record := range records {
+			file = append(file, privacyRecord{Namespace: string(record.Namespace), Key: record.Key, Value: record.Value})
+		}
+
+		data, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return errors.NewInternalError("failed to encode user records", err)
+		}
+
+		message.Files = []*discordgo.File{
+			{Name: "your-data.json", ContentType: "application/json", Reader: bytes.NewReader(data)},
+		}
+	}
+
+	return b.sendPrivately(ctx, s, m, message)
+}
+
+// setPrivacyOptOut opts a user out of a tracking category, or all of them, or back in.
+func (b *Bot) setPrivacyOptOut(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, name string, optOut bool) error {
+	loc := i18n.FromContext(ctx)
+	categories := privacy.Categories
+
+	if !strings.EqualFold(name, "all") {
+		category, err := privacy.ParseCategory(name)
+		if err != nil {
+			b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("privacy.unknown_category", name, categoriesDisplay()))
+			return nil
+		}
+
+		categories = []privacy.Category{category}
+	}
+
+	if err := b.privacy.SetOptOut(m.Author.ID, categories, optOut); err != nil {
+		return err
+	}
+
+	optOuts, err := b.privacy.OptOuts(m.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	action := "opt-in"
+	if optOut {
+		action = "opt-out"
+	}
+
+	privacyLogger(m, action).InfoContext(ctx, "User changed their privacy choices", "category", strings.ToLower(name))
+
+	embed := &discordgo.MessageEmbed{
+		Title: loc.T("privacy." + action + "_title"),
+		Color: 0x2ECC71, // Green color.
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:  loc.T("privacy.tracking"),
+				Value: trackingDisplay(loc, optOuts),
+			},
+		},
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send privacy choices", err)
+	}
+
+	return nil
+}
+
+// deletePrivacyRecords deletes every record stored about a user.
+func (b *Bot) deletePrivacyRecords(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate) error {
+	deleted, err := b.privacy.Delete(m.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	// The language choice was deleted with the other records.
+	b.locales.Forget(m.Author.ID)
+
+	privacyLogger(m, "delete").InfoContext(ctx, "Deleted user data", "records", deleted)
+
+	loc := i18n.FromContext(ctx)
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("privacy.deleted_title"),
+		Description: loc.N("privacy.deleted", int64(deleted), loc.Int(int64(deleted))),
+		Color:       0x2ECC71, // Green color.
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send deletion report", err)
+	}
+
+	return nil
+}
+
+// sendPrivately sends a message to the author in a direct message, and tells them in the
+// channel when the command was used in a server.
+func (b *Bot) sendPrivately(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, message *discordgo.MessageSend) error {
+	channelID := m.ChannelID
+
+	if m.GuildID != "" {
+		channel, err := s.UserChannelCreate(m.Author.ID, discordgo.WithContext(ctx))
+		if err != nil {
+			return errors.NewDiscordError("failed to open direct message channel", err)
+		}
+
+		channelID = channel.ID
+	}
+
+	if _, err := s.ChannelMessageSendComplex(channelID, message, discordgo.WithContext(ctx)); err != nil {
+		if m.GuildID != "" {
+			b.sendErrorMessage(ctx, s, m.ChannelID, i18n.FromContext(ctx).T("privacy.dm_failed"))
+			return nil
+		}
+
+		return errors.NewDiscordError("failed to send direct message", err)
+	}
+
+	if m.GuildID != "" {
+		if _, err := s.ChannelMessageSend(m.ChannelID, i18n.FromContext(ctx).T("privacy.dm_sent"), discordgo.WithContext(ctx)); err != nil {
+			return errors.NewDiscordError("failed to send confirmation", err)
+		}
+	}
+
+	return nil
+}
+
+// trackingDisplay lists every tracking category and whether the user opted out of it.
+func trackingDisplay(loc *i18n.Localizer, optOuts []privacy.Category) string {
+	lines := make([]string, 0, len(privacy.Categories))
+
+	for _, category := range privacy.Categories {
+		state := loc.T("privacy.on")
+
+		for _, optedOut := range optOuts {
+			if optedOut == category {
+				state = loc.T("privacy.off")
+			}
+		}
+
+		lines = append(lines, fmt.Sprintf("`%s`: %s", category, state))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// categoriesDisplay lists the tracking categories.
+func categoriesDisplay() string {
+	names := make([]string, 0, len(privacy.Categories))
+	for _, category := range privacy.Categories {
+		names = append(names, "`"+string(category)+"`")
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// authorLogger returns a logger identifying the author of a message, unless they opted out
+// of message logs.
+func (b *Bot) authorLogger(m *discordgo.MessageCreate) *slog.Logger {
+	logger := logging.WithComponent("discord")
+	if b.privacy.OptedOut(m.Author.ID, privacy.MessageLogs) {
+		return logger
+	}
+
+	return logger.With("user_id", m.Author.ID, "username", m.Author.Username)
+}
+
+// commandLogger returns the logger of a command handler, identifying the author like
+// authorLogger.
+func (b *Bot) commandLogger(m *discordgo.MessageCreate, command string) *slog.Logger {
+	return b.authorLogger(m).With("command", command)
+}
+
+// privacyLogger returns the audit logger of a privacy request.
+func privacyLogger(m *discordgo.MessageCreate, action string) *slog.Logger {
+	return logging.WithComponent("privacy").With(
+		"user_id", m.Author.ID,
+		"command", "privacy",
+		"action", action,
+		"guild_id", m.GuildID,
+	)
+}
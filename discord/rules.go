@@ -0,0 +1,230 @@
+package discord
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/logging"
+	"github.com/dunamismax/discogo/rules"
+	"github.com/dunamismax/discogo/settings"
+)
+
+// blockedReason returns why a command cannot be used where it was sent, or "" when it can.
+// Commands are never blocked in direct messages, and protected commands never are, so
+// administrators cannot lock themselves out. When the rules cannot be read, commands are
+// allowed so they keep working.
+func (b *Bot) blockedReason(ctx context.Context, m *discordgo.MessageCreate, guild *settings.Guild, command string) string {
+	if m.GuildID == "" || settings.Protected(command) {
+		return ""
+	}
+
+	loc := i18n.FromContext(ctx)
+
+	if !guild.CommandEnabled(command) {
+		return loc.T("rules.disabled_server", guild.Prefix, command)
+	}
+
+	decision, err := b.rules.Check(m.GuildID, m.ChannelID, command, b.commandCategories[command])
+	if err != nil {
+		logger := logging.WithComponent("discord").With("guild_id", m.GuildID)
+		logging.LogErrorContext(ctx, logger, err, "Failed to check command rules, allowing the command")
+
+		return ""
+	}
+
+	switch {
+	case decision.Allowed:
+		return ""
+	case len(decision.Channels) > 0:
+		mentions := make([]string, 0, len(decision.Channels))
+		for _, channelID := range decision.Channels {
+			mentions = append(mentions, "<#"+channelID+">")
+		}
+
+		return loc.T("rules.only_in", guild.Prefix, command, strings.Join(mentions, ", "))
+	case decision.Rule.ChannelID != "":
+		return loc.T("rules.disabled_channel", guild.Prefix, command)
+	default:
+		return loc.T("rules.disabled_server", guild.Prefix, command)
+	}
+}
+
+// handleRules handles the !rules command, with which server administrators allow or deny
+// commands and categories of commands in the server or in single channels.
+func (b *Bot) handleRules(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	loc := i18n.FromContext(ctx)
+	prefix := b.prefix(m.GuildID)
+
+	var subcommand string
+	if len(args) > 0 {
+		subcommand = strings.ToLower(args[0])
+	}
+
+	switch {
+	case subcommand == "list" && len(args) == 1:
+		return b.sendRulesList(ctx, s, m)
+	case (subcommand == "allow" || subcommand == "deny" || subcommand == "remove") && (len(args) == 2 || len(args) == 3):
+	default:
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("rules.usage", prefix, strings.Join(commandCategoryNames, ", ")))
+		return nil
+	}
+
+	target, err := b.ruleTarget(loc, args[1])
+	rule := rules.Rule{Target: target}
+
+	if err == nil && len(args) == 3 {
+		// Rules of deleted channels can still be removed.
+		rule.ChannelID, err = b.ruleChannel(loc, args[2], m.GuildID, subcommand != "remove")
+	}
+
+	if err != nil {
+		if message, ok := loc.Error(err); ok {
+			b.sendErrorMessage(ctx, s, m.ChannelID, message)
+			return nil
+		}
+
+		return err
+	}
+
+	return b.changeRule(ctx, s, m, subcommand, rule)
+}
+
+// changeRule sets or removes a rule and reports the change.
+func (b *Bot) changeRule(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, subcommand string, rule rules.Rule) error {
+	loc := i18n.FromContext(ctx)
+
+	// describe renders the change, for the reply and the guild's log channel.
+	var describe func(loc *i18n.Localizer) string
+
+	if subcommand == "remove" {
+		removed, err := b.rules.Remove(m.GuildID, rule.ChannelID, rule.Target)
+		if err != nil {
+			return err
+		}
+
+		if !removed {
+			b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("rules.no_rule", ruleDisplay(loc, rule)))
+			return nil
+		}
+
+		describe = func(loc *i18n.Localizer) string {
+			return loc.T("rules.removed", ruleDisplay(loc, rule))
+		}
+	} else {
+		rule.Effect = rules.Effect(subcommand)
+
+		if err := b.rules.Set(m.GuildID, rule); err != nil {
+			return err
+		}
+
+		describe = func(loc *i18n.Localizer) string {
+			return loc.T("rules.set."+string(rule.Effect), ruleDisplay(loc, rule))
+		}
+	}
+
+	logger := b.commandLogger(m, "rules").With("guild_id", m.GuildID)
+	logger.InfoContext(ctx, "Command rule changed", "action", subcommand, "target", rule.Target, "channel_id", rule.ChannelID)
+
+	b.logSettingsChange(ctx, s, m, describe)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("rules.title"),
+		Description: describe(loc),
+		Color:       0x2ECC71, // Green color.
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send rule change", err)
+	}
+
+	return nil
+}
+
+// sendRulesList sends every command rule of the guild.
+func (b *Bot) sendRulesList(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate) error {
+	list, err := b.rules.List(m.GuildID)
+	if err != nil {
+		return err
+	}
+
+	loc := i18n.FromContext(ctx)
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("rules.title"),
+		Description: loc.T("rules.empty"),
+		Color:       0x3498DB, // Blue color.
+	}
+
+	if len(list) > 0 {
+		lines := make([]string, 0, len(list))
+		for _, rule := range list {
+			lines = append(lines, loc.T("rules.line."+string(rule.Effect), ruleDisplay(loc, rule)))
+		}
+
+		embed.Description = truncateField(strings.Join(lines, "\n"))
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send command rules", err)
+	}
+
+	return nil
+}
+
+// ruleTarget validates the command or category:name target of a rule.
+func (b *Bot) ruleTarget(loc *i18n.Localizer, arg string) (string, error) {
+	arg = strings.ToLower(arg)
+
+	if category, ok := strings.CutPrefix(arg, "category:"); ok {
+		for _, name := range commandCategoryNames {
+			if category == name {
+				return rules.CategoryTarget(category), nil
+			}
+		}
+
+		return "", errors.NewValidationError(loc.T("rules.unknown_category", category, strings.Join(commandCategoryNames, ", ")))
+	}
+
+	if _, exists := b.commandHandlers[arg]; !exists {
+		return "", errors.NewValidationError(loc.T("error.no_command", arg))
+	}
+
+	if settings.Protected(arg) {
+		return "", errors.NewValidationError(loc.T("rules.protected", arg))
+	}
+
+	return arg, nil
+}
+
+// ruleChannel validates the channel of a rule, given as a mention or ID. When mustExist is
+// set, it must be a channel of the guild.
+func (b *Bot) ruleChannel(loc *i18n.Localizer, arg, guildID string, mustExist bool) (string, error) {
+	channelID := strings.TrimSuffix(strings.TrimPrefix(arg, "<#"), ">")
+
+	if !mustExist {
+		return channelID, nil
+	}
+
+	channel, err := b.session.State.Channel(channelID)
+	if err != nil || channel.GuildID != guildID {
+		return "", errors.NewValidationError(loc.T("rules.unknown_channel", arg))
+	}
+
+	return channelID, nil
+}
+
+// ruleDisplay describes the target and scope of a rule.
+func ruleDisplay(loc *i18n.Localizer, rule rules.Rule) string {
+	target := "`" + rule.Target + "`"
+	if category, ok := rule.Category(); ok {
+		target = loc.T("rules.category", category)
+	}
+
+	if rule.ChannelID == "" {
+		return loc.T("rules.in_server", target)
+	}
+
+	return loc.T("rules.in_channel", target, rule.ChannelID)
+}
@@ -0,0 +1,248 @@
+package discord
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/logging"
+	"github.com/dunamismax/discogo/settings"
+)
+
+// guildSettings returns the effective settings of a guild, or the defaults for direct
+// messages. When the settings cannot be read, the defaults are used so commands keep working.
+func (b *Bot) guildSettings(guildID string) *settings.Guild {
+	if guildID == "" {
+		return b.settings.Defaults()
+	}
+
+	guild, err := b.settings.Guild(guildID)
+	if err != nil {
+		logger := logging.WithComponent("discord").With("guild_id", guildID)
+		logging.LogError(logger, err, "Failed to load guild settings, using defaults")
+
+		return b.settings.Defaults()
+	}
+
+	return guild
+}
+
+// prefix returns the command prefix of a guild.
+func (b *Bot) prefix(guildID string) string {
+	return b.guildSettings(guildID).Prefix
+}
+
+// handleSettings handles the !settings command, with which server administrators manage
+// the settings of their server.
+func (b *Bot) handleSettings(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	loc := i18n.FromContext(ctx)
+	prefix := b.prefix(m.GuildID)
+
+	var subcommand string
+	if len(args) > 0 {
+		subcommand = strings.ToLower(args[0])
+	}
+
+	var (
+		entry settings.Entry
+		err   error
+	)
+
+	switch {
+	case subcommand == "list" && len(args) == 1:
+		return b.sendSettingsList(ctx, s, m)
+	case subcommand == "get" && len(args) == 2:
+		entry, err = b.settings.Get(m.GuildID, args[1])
+	case subcommand == "set" && len(args) >= 3:
+		value := strings.Join(args[2:], " ")
+
+		if err = b.validateSetting(loc, args[1], value); err == nil {
+			entry, err = b.settings.Set(m.GuildID, args[1], value)
+		}
+	case subcommand == "reset" && len(args) == 2 && strings.EqualFold(args[1], "all"):
+		if err := b.settings.ResetAll(m.GuildID); err != nil {
+			return err
+		}
+
+		b.logSettingsChange(ctx, s, m, func(loc *i18n.Localizer) string { return loc.T("settings.reset_all") })
+
+		return b.sendSettingsList(ctx, s, m)
+	case subcommand == "reset" && len(args) == 2:
+		entry, err = b.settings.Reset(m.GuildID, args[1])
+	default:
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("settings.usage", prefix))
+		return nil
+	}
+
+	if err != nil {
+		if message, ok := loc.Error(err); ok {
+			if _, known := settings.Lookup(args[1]); !known {
+				message += " " + loc.T("settings.list_hint", prefix)
+			}
+
+			b.sendErrorMessage(ctx, s, m.ChannelID, message)
+
+			return nil
+		}
+
+		return err
+	}
+
+	logger := b.commandLogger(m, "settings").With("guild_id", m.GuildID)
+
+	switch subcommand {
+	case "set":
+		logger.InfoContext(ctx, "Guild setting changed", "setting", entry.Key, "value", entry.Value)
+		b.logSettingsChange(ctx, s, m, func(loc *i18n.Localizer) string {
+			return loc.T("settings.changed_set", entry.Key, settingDisplay(loc, entry))
+		})
+	case "reset":
+		logger.InfoContext(ctx, "Guild setting reset", "setting", entry.Key)
+		b.logSettingsChange(ctx, s, m, func(loc *i18n.Localizer) string {
+			return loc.T("settings.changed_reset", entry.Key, settingDisplay(loc, entry))
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "⚙️ " + entry.Key,
+		Description: settingDescription(loc, entry),
+		Color:       0x3498DB, // Blue color.
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   loc.T("settings.value"),
+				Value:  settingDisplay(loc, entry),
+				Inline: true,
+			},
+		},
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send setting", err)
+	}
+
+	return nil
+}
+
+// validateSetting checks what the settings package cannot know: that disabled commands exist.
+func (b *Bot) validateSetting(loc *i18n.Localizer, key, value string) error {
+	definition, ok := settings.Lookup(key)
+	if !ok || definition.Key != "disabled_commands" {
+		return nil
+	}
+
+	normalized, err := definition.Normalize(value)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range strings.Split(normalized, ",") {
+		if _, exists := b.commandHandlers[command]; command != "" && !exists {
+			return errors.NewValidationError(loc.T("error.no_command", command))
+		}
+	}
+
+	return nil
+}
+
+// sendSettingsList sends every setting of the guild with its value.
+func (b *Bot) sendSettingsList(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate) error {
+	entries, err := b.settings.List(m.GuildID)
+	if err != nil {
+		return err
+	}
+
+	loc := i18n.FromContext(ctx)
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("settings.title"),
+		Description: loc.T("settings.description", b.prefix(m.GuildID)),
+		Color:       0x3498DB, // Blue color.
+	}
+
+	for _, entry := range entries {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   entry.Key,
+			Value:  truncateField(settingDisplay(loc, entry) + "\n" + settingDescription(loc, entry)),
+			Inline: false,
+		})
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx)); err != nil {
+		return errors.NewDiscordError("failed to send settings", err)
+	}
+
+	return nil
+}
+
+// logSettingsChange posts a notice of a settings change to the guild's log channel, if any.
+// change describes the change; the notice is in the guild's language, not the author's.
+func (b *Bot) logSettingsChange(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, change func(loc *i18n.Localizer) string) {
+	guild := b.guildSettings(m.GuildID)
+	if guild.LogChannelID == "" {
+		return
+	}
+
+	channelID := guild.LogChannelID
+	loc := i18n.Resolve(guild.Locale)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("settings.changed_title"),
+		Description: change(loc),
+		Color:       0x3498DB, // Blue color.
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: loc.T("settings.changed_by", m.Author.Username),
+		},
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(channelID, embed, discordgo.WithContext(ctx)); err != nil {
+		logger := logging.WithComponent("discord").With("guild_id", m.GuildID, "channel_id", channelID)
+		logger.WarnContext(ctx, "Failed to post to the guild log channel", "error", err)
+	}
+}
+
+// settingDisplay formats the value of a setting for an embed.
+func settingDisplay(loc *i18n.Localizer, entry settings.Entry) string {
+	var value string
+
+	switch {
+	case entry.Value == "":
+		value = loc.T("settings.not_set")
+	case entry.Key == "log_channel":
+		value = "<#" + entry.Value + ">"
+	case entry.Key == "moderation.mod_role":
+		value = "<@&" + entry.Value + ">"
+	default:
+		value = "`" + entry.Value + "`"
+	}
+
+	if entry.Inherited {
+		value = loc.T("settings.inherited", value)
+	}
+
+	return value
+}
+
+// settingDescription returns the translated description of a setting, or the schema's
+// English one when the catalogs have none.
+func settingDescription(loc *i18n.Localizer, entry settings.Entry) string {
+	key := "settings.setting." + entry.Key
+
+	var description string
+
+	switch entry.Key {
+	case "prefix":
+		description = loc.T(key, settings.MaxPrefixLength)
+	case "moderation.max_mentions":
+		description = loc.T(key, config.MaxMentionsLimit)
+	default:
+		description = loc.T(key)
+	}
+
+	if description == key {
+		return entry.Description
+	}
+
+	return description
+}
@@ -0,0 +1,379 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/chart"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/metrics"
+)
+
+// topCommandsLimit is the number of commands listed in the top commands section of !stats.
+const topCommandsLimit = 5
+
+// statsGraphFilename is the attachment name of the !stats graph image.
+const statsGraphFilename = "stats.png"
+
+// maxEmbedFieldLength is Discord's limit on the length of an embed field value.
+const maxEmbedFieldLength = 1024
+
+// handleStats handles the !stats command.
+func (b *Bot) handleStats(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	if len(args) > 0 {
+		if strings.EqualFold(args[0], "graph") {
+			return b.handleStatsGraph(ctx, s, m, args[1:])
+		}
+
+		return b.handleCommandStats(ctx, s, m, args[0])
+	}
+
+	logger := b.commandLogger(m, "stats")
+	logger.InfoContext(ctx, "Showing bot statistics")
+
+	loc := i18n.FromContext(ctx)
+	summary := metrics.Get().GetSummary()
+	uptime := time.Duration(summary.UptimeSeconds * float64(time.Second))
+
+	embed := &discordgo.MessageEmbed{
+		Title: loc.T("stats.title"),
+		Color: 0x2ECC71, // Green color.
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name: loc.T("stats.commands"),
+				Value: loc.T("stats.commands.value", loc.Int(summary.CommandsTotal), loc.Int(summary.CommandsSuccessful),
+					loc.Int(summary.CommandsFailed), loc.Percent(summary.CommandSuccessRate)),
+				Inline: true,
+			},
+			{
+				Name: loc.T("stats.api"),
+				Value: loc.T("stats.api.value", loc.Int(summary.APIRequestsTotal), loc.Percent(summary.APISuccessRate),
+					loc.Float(summary.AverageResponseTime, 0)),
+				Inline: true,
+			},
+			{
+				Name: loc.T("stats.performance"),
+				Value: loc.T("stats.performance.value",
+					loc.Float(summary.CommandRates.OneMinute, 2), loc.Float(summary.CommandRates.FiveMinutes, 2),
+					loc.Float(summary.CommandRates.FifteenMinutes, 2), loc.Float(summary.APIRequestRates.OneMinute, 2),
+					loc.Float(summary.APIRequestRates.FiveMinutes, 2), loc.Float(summary.APIRequestRates.FifteenMinutes, 2)),
+				Inline: true,
+			},
+			{
+				Name:   loc.T("stats.uptime"),
+				Value:  loc.Duration(uptime),
+				Inline: true,
+			},
+			{
+				Name:   loc.T("stats.started"),
+				Value:  fmt.Sprintf("<t:%d:R>", time.Now().Add(-uptime).Unix()),
+				Inline: true,
+			},
+			{
+				Name: loc.T("stats.health"),
+				Value: loc.T("stats.health.value",
+					formatLatency(loc, summary.GatewayLatency.P50), formatLatency(loc, summary.GatewayLatency.P95),
+					formatLatency(loc, summary.GatewayLatency.P99), loc.Int(int64(summary.Runtime.Goroutines)),
+					formatBytes(loc, summary.Runtime.HeapAllocBytes),
+					formatLatency(loc, summary.Runtime.GCPauseP99), formatLatency(loc, summary.Runtime.GCPauseLast)),
+				Inline: true,
+			},
+			{
+				Name: loc.T("stats.lifetime"),
+				Value: loc.T("stats.lifetime.value", loc.Int(summary.Lifetime.CommandsTotal),
+					loc.Percent(summary.Lifetime.CommandSuccessRate), loc.Int(summary.Lifetime.APIRequestsTotal),
+					loc.Duration(time.Duration(summary.Lifetime.UptimeSeconds*float64(time.Second)))),
+				Inline: true,
+			},
+		},
+	}
+
+	if firstStart, err := time.Parse(time.RFC3339, summary.Lifetime.FirstStartTime); err == nil {
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: loc.N("stats.footer", summary.Lifetime.Starts, loc.Int(summary.Lifetime.Starts), loc.Date(firstStart)),
+		}
+	}
+
+	// Add the most used commands.
+	if topCommands := summary.Commands; len(topCommands) > 0 {
+		if len(topCommands) > topCommandsLimit {
+			topCommands = topCommands[:topCommandsLimit]
+		}
+
+		lines := make([]string, 0, len(topCommands))
+		for i, stats := range topCommands {
+			lines = append(lines, loc.N("stats.top.line", stats.Total, loc.Int(int64(i+1)), b.prefix(m.GuildID)+stats.Name,
+				loc.Int(stats.Total), loc.Percent(stats.SuccessRate), formatLatency(loc, stats.P95)))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   loc.T("stats.top"),
+			Value:  strings.Join(lines, "\n"),
+			Inline: false,
+		})
+	}
+
+	// Add metrics registered by features.
+	if customInfo := formatCustomMetrics(summary.Custom); customInfo != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   loc.T("stats.custom"),
+			Value:  customInfo,
+			Inline: false,
+		})
+	}
+
+	// Add error information if there are errors.
+	if len(summary.ErrorsByType) > 0 {
+		errorInfo := make([]string, 0, len(summary.ErrorsByType))
+		for errorType, count := range summary.ErrorsByType {
+			if count > 0 {
+				errorInfo = append(errorInfo, fmt.Sprintf("%s: %s", string(errorType), loc.Int(count)))
+			}
+		}
+
+		if len(errorInfo) > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   loc.T("stats.errors"),
+				Value:  strings.Join(errorInfo, "\n"),
+				Inline: false,
+			})
+		}
+	}
+
+	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx))
+	if err != nil {
+		return errors.NewDiscordError("failed to send stats message", err)
+	}
+
+	return nil
+}
+
+// handleCommandStats handles the !stats <command> detail view.
+func (b *Bot) handleCommandStats(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, name string) error {
+	prefix := b.prefix(m.GuildID)
+	command := strings.ToLower(strings.TrimPrefix(name, prefix))
+
+	logger := b.commandLogger(m, "stats").With("target_command", command)
+	logger.InfoContext(ctx, "Showing command statistics")
+
+	loc := i18n.FromContext(ctx)
+
+	stats, found := metrics.Get().GetCommandStats(command)
+	if !found {
+		b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("stats.command.not_found", prefix, command))
+		return nil
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: loc.T("stats.command.title", prefix, stats.Name),
+		Color: 0x2ECC71, // Green color.
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name: loc.T("stats.command.executions"),
+				Value: loc.T("stats.commands.value", loc.Int(stats.Total), loc.Int(stats.Successful), loc.Int(stats.Failed),
+					loc.Percent(stats.SuccessRate)),
+				Inline: true,
+			},
+			{
+				Name: loc.T("stats.command.latency"),
+				Value: loc.T("stats.command.latency.value", formatLatency(loc, stats.AverageDuration), formatLatency(loc, stats.P50),
+					formatLatency(loc, stats.P95), formatLatency(loc, stats.P99)),
+				Inline: true,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: loc.T("stats.command.footer"),
+		},
+	}
+
+	// Add this guild's numbers when per-guild tracking is enabled.
+	if m.GuildID != "" {
+		if guildStats, ok := metrics.Get().GetGuildCommandStats(m.GuildID, command); ok {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name: loc.T("stats.command.guild"),
+				Value: loc.T("stats.command.guild.value", loc.Int(guildStats.Total), loc.Percent(guildStats.SuccessRate),
+					formatLatency(loc, guildStats.P95)),
+				Inline: true,
+			})
+		}
+	}
+
+	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed, discordgo.WithContext(ctx))
+	if err != nil {
+		return errors.NewDiscordError("failed to send command stats message", err)
+	}
+
+	return nil
+}
+
+// handleStatsGraph handles the !stats graph [hours] command.
+func (b *Bot) handleStatsGraph(ctx context.Context, s *discordgo.Session, m *discordgo.MessageCreate, args []string) error {
+	logger := b.commandLogger(m, "stats")
+
+	loc := i18n.FromContext(ctx)
+
+	hours := int(metrics.HistoryRetention / time.Hour)
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(args[0]), "h"))
+		if err != nil || parsed < 1 || parsed > hours {
+			b.sendErrorMessage(ctx, s, m.ChannelID, loc.T("stats.graph.usage", b.prefix(m.GuildID), loc.Int(int64(hours))))
+			return nil
+		}
+
+		hours = parsed
+	}
+
+	logger.InfoContext(ctx, "Rendering statistics graph", "hours", hours)
+
+	window := time.Duration(hours) * time.Hour
+	points := metrics.Get().GetHistory(window)
+
+	commands := make([]float64, len(points))
+	errorCounts := make([]float64, len(points))
+	latencies := make([]float64, len(points))
+
+	var totalCommands, totalErrors int64
+
+	for i, point := range points {
+		commands[i] = float64(point.Commands)
+		errorCounts[i] = float64(point.Errors)
+		latencies[i] = math.NaN()
+
+		if point.APIRequests > 0 {
+			latencies[i] = point.AverageLatency
+		}
+
+		totalCommands += point.Commands
+		totalErrors += point.Errors
+	}
+
+	// The chart font only has ASCII characters, which the catalogs keep to for these messages.
+	perMinute := loc.T("stats.graph.per_minute")
+	graph := &chart.Chart{
+		Title: loc.T("stats.graph.chart_title", b.Config().BotName, loc.Int(int64(hours))),
+		Start: points[0].Time,
+		End:   points[len(points)-1].Time,
+		Panels: []chart.Panel{
+			{Title: loc.T("stats.graph.commands_panel"), Unit: perMinute, Color: color.RGBA{R: 0x58, G: 0x65, B: 0xF2, A: 0xFF}, Values: commands},
+			{Title: loc.T("stats.graph.errors_panel"), Unit: perMinute, Color: color.RGBA{R: 0xE7, G: 0x4C, B: 0x3C, A: 0xFF}, Values: errorCounts},
+			{Title: loc.T("stats.graph.latency_panel"), Unit: loc.T("stats.graph.latency_unit"), Color: color.RGBA{R: 0xF1, G: 0xC4, B: 0x0F, A: 0xFF}, Values: latencies},
+		},
+	}
+
+	var image bytes.Buffer
+	if err := graph.Render(&image); err != nil {
+		return errors.NewInternalError("failed to render statistics graph", err)
+	}
+
+	description := loc.T("stats.graph.description", loc.Int(int64(hours)),
+		loc.N("stats.graph.commands", totalCommands, loc.Int(totalCommands)), loc.N("stats.graph.errors", totalErrors, loc.Int(totalErrors)))
+
+	embed := &discordgo.MessageEmbed{
+		Title:       loc.T("stats.graph.title"),
+		Description: description,
+		Color:       0x2ECC71, // Green color.
+		Image: &discordgo.MessageEmbedImage{
+			URL: "attachment://" + statsGraphFilename,
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: loc.T("stats.graph.footer"),
+		},
+	}
+
+	_, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files: []*discordgo.File{
+			{Name: statsGraphFilename, ContentType: "image/png", Reader: &image},
+		},
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return errors.NewDiscordError("failed to send stats graph", err)
+	}
+
+	return nil
+}
+
+// formatCustomMetrics summarizes registered metrics, one line per metric, within the embed field limit.
+func formatCustomMetrics(families []metrics.FamilySnapshot) string {
+	lines := make([]string, 0, len(families))
+	length := 0
+
+	for _, f := range families {
+		var line string
+
+		switch f.Type {
+		case metrics.MetricTypeHistogram:
+			var count uint64
+
+			var sum float64
+
+			for _, s := range f.Series {
+				count += s.Histogram.Count
+				sum += s.Histogram.Sum
+			}
+
+			if count == 0 {
+				continue
+			}
+
+			line = fmt.Sprintf("%s: %d observations, avg %.3g", f.Name, count, sum/float64(count))
+		default:
+			if len(f.Series) == 0 {
+				continue
+			}
+
+			line = fmt.Sprintf("%s: %s", f.Name, strconv.FormatFloat(f.Total(), 'f', -1, 64))
+		}
+
+		if length+len(line)+1 > maxEmbedFieldLength {
+			lines = append(lines, "…")
+			break
+		}
+
+		lines = append(lines, line)
+		length += len(line) + 1
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatBytes formats a byte count using binary units.
+func formatBytes(loc *i18n.Localizer, size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return loc.Int(int64(size)) + " B"
+	}
+
+	value := float64(size)
+	suffixes := []string{"KiB", "MiB", "GiB", "TiB"}
+
+	var suffix string
+	for _, suffix = range suffixes {
+		value /= unit
+		if value < unit {
+			break
+		}
+	}
+
+	return loc.Float(value, 1) + " " + suffix
+}
+
+// formatLatency formats a latency with a precision suited to its magnitude.
+func formatLatency(loc *i18n.Localizer, d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return loc.Float(d.Seconds(), 2) + "s"
+	case d >= time.Millisecond:
+		return loc.Int(d.Milliseconds()) + "ms"
+	default:
+		return loc.Int(d.Microseconds()) + "µs"
+	}
+}
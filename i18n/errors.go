@@ -0,0 +1,82 @@
+package i18n
+
+import (
+	stdErrors "errors"
+	"strings"
+
+	"github.com/dunamismax/discogo/errors"
+)
+
+// Keys of the context of validation errors made by ValidationError.
+const (
+	messageKey  = "message_key"
+	messageArgs = "message_args"
+)
+
+// ValidationError returns a validation error whose message is the catalog entry key,
+// formatted with args. Its Message is in DefaultLocale, for logs and the command line;
+// Localizer.Error translates it for users. Integer arguments are formatted with
+// Localizer.Int, so the catalog entries take them as %s. Errors among the arguments, alone
+// or in a slice, are translated the same way.
+func ValidationError(key string, args ...any) *errors.BotError {
+	err := errors.NewValidationError("")
+	err.Context = map[string]interface{}{messageKey: key, messageArgs: args}
+	err.Message = Default().message(key, args)
+
+	return err
+}
+
+// Error returns the message of a validation error in the localizer's language. Other errors
+// are not meant for the user, and Error reports false for them.
+func (l *Localizer) Error(err error) (string, bool) {
+	var botErr *errors.BotError
+	if !stdErrors.As(err, &botErr) || botErr.Type != errors.ErrorTypeValidation {
+		return "", false
+	}
+
+	key, ok := botErr.Context[messageKey].(string)
+	if !ok {
+		return botErr.Message, true
+	}
+
+	args, _ := botErr.Context[messageArgs].([]any)
+
+	return l.message(key, args), true
+}
+
+// message translates a message with the arguments of a validation error.
+func (l *Localizer) message(key string, args []any) string {
+	formatted := make([]any, len(args))
+
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case int:
+			formatted[i] = l.Int(int64(arg))
+		case int64:
+			formatted[i] = l.Int(arg)
+		case error:
+			formatted[i] = l.part(arg)
+		case []error:
+			parts := make([]string, len(arg))
+			for j, err := range arg {
+				parts[j] = l.part(err)
+			}
+
+			formatted[i] = strings.Join(parts, "; ")
+		default:
+			formatted[i] = arg
+		}
+	}
+
+	return l.T(key, formatted...)
+}
+
+// part translates an error that is part of another message, without its trailing period.
+func (l *Localizer) part(err error) string {
+	message, ok := l.Error(err)
+	if !ok {
+		message = err.Error()
+	}
+
+	return strings.TrimSuffix(message, ".")
+}
@@ -0,0 +1,100 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Int formats an integer with the language's digit grouping, such as 1,234 or 1.234.
+func (l *Localizer) Int(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	return sign + l.group(digits)
+}
+
+// Float formats a number with the given number of decimals, and the language's digit
+// grouping and decimal separator, such as 1,234.5 or 1.234,5.
+func (l *Localizer) Float(value float64, decimals int) string {
+	formatted := strconv.FormatFloat(value, 'f', decimals, 64)
+
+	sign := ""
+	if strings.HasPrefix(formatted, "-") {
+		sign, formatted = "-", formatted[1:]
+	}
+
+	whole, fraction, ok := strings.Cut(formatted, ".")
+
+	formatted = sign + l.group(whole)
+	if ok {
+		formatted += l.T("number.decimal") + fraction
+	}
+
+	return formatted
+}
+
+// Percent formats a percentage between 0 and 100 with one decimal, such as 97.5% or 97,5 %.
+func (l *Localizer) Percent(value float64) string {
+	return l.T("number.percent", l.Float(value, 1))
+}
+
+// Duration formats a duration down to the second, such as 1d 2h 3m 4s.
+func (l *Localizer) Duration(d time.Duration) string {
+	days := int64(d.Hours()) / 24
+	hours := int64(d.Hours()) % 24
+	minutes := int64(d.Minutes()) % 60
+	seconds := int64(d.Seconds()) % 60
+
+	units := []struct {
+		key   string
+		value int64
+	}{
+		{"duration.days", days},
+		{"duration.hours", hours},
+		{"duration.minutes", minutes},
+		{"duration.seconds", seconds},
+	}
+
+	// Leading zero units are left out; the seconds are always shown.
+	for len(units) > 1 && units[0].value == 0 {
+		units = units[1:]
+	}
+
+	parts := make([]string, 0, len(units))
+	for _, unit := range units {
+		parts = append(parts, l.N(unit.key, unit.value, l.Int(unit.value)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Date formats a date and time in UTC, such as Jan 2, 2006 15:04 UTC or 02.01.2006 15:04 UTC.
+func (l *Localizer) Date(t time.Time) string {
+	return t.UTC().Format(l.T("date.layout"))
+}
+
+// group inserts the language's group separator into a string of digits.
+func (l *Localizer) group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	separator := l.T("number.group")
+
+	var grouped strings.Builder
+
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteString(separator)
+		}
+
+		grouped.WriteRune(digit)
+	}
+
+	return grouped.String()
+}
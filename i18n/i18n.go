@@ -0,0 +1,226 @@
+// Package i18n translates the bot's replies and formats numbers, durations and dates for
+// the reader's language.
+//
+// Every language has a catalog in locales/, embedded in the binary: a JSON object mapping
+// message keys to fmt format strings, or to plural forms such as {"one": …, "other": …}
+// for messages that depend on a count. Messages missing from a catalog fall back to
+// English. Add a language by adding its catalog, named after its Discord locale or base
+// language, such as de.json or pt-BR.json, and its plural rule in plural.go.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the language used when no preferred language has a catalog.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// message is a catalog entry: its plural forms, or only "other" for plain messages.
+type message map[string]string
+
+// UnmarshalJSON accepts a string for plain messages and an object for plural forms.
+func (m *message) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = message{"other": text}
+		return nil
+	}
+
+	var forms map[string]string
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return err
+	}
+
+	if _, ok := forms["other"]; !ok {
+		return fmt.Errorf("plural forms %v lack the other form", forms)
+	}
+
+	*m = forms
+
+	return nil
+}
+
+// catalogs maps catalog names, such as en or pt-BR, to their messages.
+var catalogs = mustLoad()
+
+// mustLoad reads the embedded catalogs. A broken catalog is a programming error.
+func mustLoad() map[string]map[string]message {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := make(map[string]map[string]message, len(entries))
+
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		var messages map[string]message
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("invalid catalog %s: %v", entry.Name(), err))
+		}
+
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+
+	if _, ok := loaded[DefaultLocale]; !ok {
+		panic("missing catalog " + DefaultLocale + ".json")
+	}
+
+	return loaded
+}
+
+// Language is a language with a catalog.
+type Language struct {
+	// Locale is the catalog name, such as en or pt-BR.
+	Locale string
+	// Name is the name of the language in the language itself.
+	Name string
+}
+
+// String returns the name and locale of the language, such as "Deutsch (de)".
+func (l Language) String() string {
+	return fmt.Sprintf("%s (%s)", l.Name, l.Locale)
+}
+
+// Languages returns every language with a catalog, sorted by locale.
+func Languages() []Language {
+	languages := make([]Language, 0, len(catalogs))
+
+	for locale := range catalogs {
+		languages = append(languages, Language{Locale: locale, Name: New(locale).T("language.name")})
+	}
+
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i].Locale < languages[j].Locale
+	})
+
+	return languages
+}
+
+// Match returns the catalog for a Discord locale such as en-US or de: the catalog with that
+// exact name, or the one of its base language. It reports whether there is one.
+func Match(locale string) (string, bool) {
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		return "", false
+	}
+
+	for name := range catalogs {
+		if strings.EqualFold(name, locale) {
+			return name, true
+		}
+	}
+
+	base, _, _ := strings.Cut(locale, "-")
+	for name := range catalogs {
+		if strings.EqualFold(name, base) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// Localizer translates messages and formats values for one language. It is safe for
+// concurrent use.
+type Localizer struct {
+	locale   string
+	messages map[string]message
+}
+
+// New returns a localizer for a Discord locale, or for DefaultLocale when the locale has no
+// catalog.
+func New(locale string) *Localizer {
+	name, ok := Match(locale)
+	if !ok {
+		name = DefaultLocale
+	}
+
+	return &Localizer{locale: name, messages: catalogs[name]}
+}
+
+// Default returns a localizer for DefaultLocale.
+func Default() *Localizer {
+	return New(DefaultLocale)
+}
+
+// Resolve returns a localizer for the first locale with a catalog, in order of preference,
+// or for DefaultLocale when none has one. Empty locales are skipped.
+func Resolve(locales ...string) *Localizer {
+	for _, locale := range locales {
+		if _, ok := Match(locale); ok {
+			return New(locale)
+		}
+	}
+
+	return Default()
+}
+
+// Locale returns the catalog name of the localizer's language.
+func (l *Localizer) Locale() string {
+	return l.locale
+}
+
+// T translates a message and formats it with args, like fmt.Sprintf.
+func (l *Localizer) T(key string, args ...any) string {
+	return l.format(key, "other", args)
+}
+
+// N translates a message that depends on the count n, choosing its plural form by the
+// language's rules, and formats it with args. args usually include n, formatted with Int.
+func (l *Localizer) N(key string, n int64, args ...any) string {
+	return l.format(key, pluralForm(l.locale, n), args)
+}
+
+// format looks up the form of a message, falling back to English and then to the key.
+func (l *Localizer) format(key, form string, args []any) string {
+	msg, ok := l.messages[key]
+	if !ok {
+		msg, ok = catalogs[DefaultLocale][key]
+	}
+
+	if !ok {
+		return key
+	}
+
+	text, ok := msg[form]
+	if !ok {
+		text = msg["other"]
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying a localizer, so replies deep in a command's call
+// chain use the language of the command.
+func NewContext(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the localizer carried by ctx, or the default one.
+func FromContext(ctx context.Context) *Localizer {
+	if l, ok := ctx.Value(contextKey{}).(*Localizer); ok {
+		return l
+	}
+
+	return Default()
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import "strings"
+
+// pluralRules maps base languages to the rule choosing the plural form of a count, after
+// the CLDR plural rules for integers. Languages without a rule use pluralOne.
+var pluralRules = map[string]func(n int64) string{
+	"fr": pluralZeroOne,
+	"pt": pluralZeroOne,
+	"ja": pluralNone,
+	"ko": pluralNone,
+	"zh": pluralNone,
+	"ru": pluralEastSlavic,
+	"uk": pluralEastSlavic,
+	"pl": pluralPolish,
+}
+
+// pluralForm returns the plural form of the count n in a language.
+func pluralForm(locale string, n int64) string {
+	base, _, _ := strings.Cut(locale, "-")
+	if rule, ok := pluralRules[strings.ToLower(base)]; ok {
+		return rule(n)
+	}
+
+	return pluralOne(n)
+}
+
+// pluralOne is the rule of English, German and most European languages: one for 1.
+func pluralOne(n int64) string {
+	if n == 1 {
+		return "one"
+	}
+
+	return "other"
+}
+
+// pluralZeroOne is the rule of French and Portuguese: one for 0 and 1.
+func pluralZeroOne(n int64) string {
+	if n == 0 || n == 1 {
+		return "one"
+	}
+
+	return "other"
+}
+
+// pluralNone is the rule of languages without plural forms.
+func pluralNone(int64) string {
+	return "other"
+}
+
+// pluralEastSlavic is the rule of Russian and Ukrainian.
+func pluralEastSlavic(n int64) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		return "one"
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return "few"
+	default:
+		return "many"
+	}
+}
+
+// pluralPolish is the rule of Polish.
+func pluralPolish(n int64) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch mod10, mod100 := n%10, n%100; {
+	case n == 1:
+		return "one"
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return "few"
+	default:
+		return "many"
+	}
+}
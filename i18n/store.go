@@ -0,0 +1,130 @@
+package i18n
+
+import (
+	"sync"
+
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/privacy"
+	"github.com/dunamismax/discogo/storage"
+)
+
+// Feature is the storage feature name of the languages users chose for the bot's replies.
+// They are kept in the feature namespace, keyed by privacy.UserKey, so privacy requests
+// find and delete them.
+const Feature = "user_locale"
+
+// Store reads and writes the languages users chose. It caches every choice, since the
+// language is looked up for every command. It is safe for concurrent use.
+type Store struct {
+	store storage.Store
+
+	mutex sync.RWMutex
+	// locales maps user IDs to catalog names; nil until loaded.
+	locales map[string]string
+	// generation counts writes and invalidations, so a load that raced with one is not cached.
+	generation uint64
+}
+
+// NewStore creates a user language store.
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// Locale returns the catalog name of the language a user chose, or "" when they did not
+// choose one.
+func (s *Store) Locale(userID string) (string, error) {
+	locales, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	// SetLocale changes the cached map in place.
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return locales[userID], nil
+}
+
+// SetLocale stores the language a user chose, or removes their choice when locale is "".
+// The locale must have a catalog.
+func (s *Store) SetLocale(userID, locale string) error {
+	if locale != "" {
+		name, ok := Match(locale)
+		if !ok {
+			return ValidationError("language.no_catalog", locale)
+		}
+
+		locale = name
+	}
+
+	err := s.store.Update(func(tx storage.Tx) error {
+		bucket := tx.Bucket(storage.FeatureNamespace(Feature))
+		if locale == "" {
+			return bucket.Delete(privacy.UserKey(userID, ""))
+		}
+
+		return bucket.Put(privacy.UserKey(userID, ""), []byte(locale))
+	})
+	if err != nil {
+		return errors.NewInternalError("failed to save language", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Update the one cached choice instead of reading every choice again.
+	if s.locales != nil {
+		if locale == "" {
+			delete(s.locales, userID)
+		} else {
+			s.locales[userID] = locale
+		}
+	}
+
+	// A load that started before the write must not replace the updated cache.
+	s.generation++
+
+	return nil
+}
+
+// Forget drops the cached choice of a user. Call it after deleting the choice from storage
+// without the Store, for example when deleting a user's data.
+func (s *Store) Forget(userID string) {
+	s.mutex.Lock()
+	delete(s.locales, userID)
+	// A load that started before the deletion may still have read the choice.
+	s.generation++
+	s.mutex.Unlock()
+}
+
+// load returns every user's choice, reading them from storage on first use.
+func (s *Store) load() (map[string]string, error) {
+	s.mutex.RLock()
+	locales := s.locales
+	generation := s.generation
+	s.mutex.RUnlock()
+
+	if locales != nil {
+		return locales, nil
+	}
+
+	locales = make(map[string]string)
+
+	err := s.store.View(func(tx storage.Tx) error {
+		return tx.Bucket(storage.FeatureNamespace(Feature)).Scan("", func(key string, value []byte) error {
+			locales[key] = string(value)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to load languages", err)
+	}
+
+	s.mutex.Lock()
+	if s.generation == generation {
+		s.locales = locales
+	}
+	s.mutex.Unlock()
+
+	return locales, nil
+}
@@ -7,12 +7,62 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"sync/atomic"
 
 	botErrors "github.com/dunamismax/discogo/errors"
 )
 
-// DefaultLogger is the global logger instance.
-var DefaultLogger *slog.Logger
+// DefaultLogger is the global logger instance. Until InitializeLogger is called
+// it logs at info level in text format, so early startup errors are not lost.
+// Every record is scrubbed of secrets before it is written.
+var DefaultLogger = slog.New(contextHandler{scrubHandler{slog.NewTextHandler(os.Stdout, nil)}})
+
+// ContextAttrsFunc extracts attributes from a context to add to log records,
+// for example the IDs of the active trace span.
+type ContextAttrsFunc func(ctx context.Context) []slog.Attr
+
+// contextAttrs holds the registered ContextAttrsFunc, if any.
+var contextAttrs atomic.Pointer[ContextAttrsFunc]
+
+// RegisterContextAttrs installs fn to enrich every record logged with a context.
+func RegisterContextAttrs(fn ContextAttrsFunc) {
+	contextAttrs.Store(&fn)
+}
+
+// attrsFromContext returns the attributes the registered ContextAttrsFunc extracts from ctx.
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	fn := contextAttrs.Load()
+	if fn == nil || ctx == nil {
+		return nil
+	}
+
+	return (*fn)(ctx)
+}
+
+// contextHandler adds context attributes to every record before passing it on.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle adds the context attributes to the record.
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := attrsFromContext(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a contextHandler whose wrapped handler has the attributes.
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a contextHandler whose wrapped handler has the group.
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
 
 // LogLevel represents the logging level.
 type LogLevel string
@@ -28,25 +78,36 @@ const (
 	LevelError LogLevel = "error"
 )
 
-// InitializeLogger initializes the global logger with the specified level and format.
-func InitializeLogger(level string, jsonFormat bool) {
-	var slogLevel slog.Level
+// level is the minimum level of the global logger. It is a LevelVar so it can be
+// changed at runtime without replacing the logger.
+var level slog.LevelVar
 
-	switch strings.ToLower(level) {
+// parseLevel converts a level name to a slog level, defaulting to info.
+func parseLevel(name string) slog.Level {
+	switch strings.ToLower(name) {
 	case "debug":
-		slogLevel = slog.LevelDebug
-	case "info":
-		slogLevel = slog.LevelInfo
+		return slog.LevelDebug
 	case "warn", "warning":
-		slogLevel = slog.LevelWarn
+		return slog.LevelWarn
 	case "error":
-		slogLevel = slog.LevelError
+		return slog.LevelError
 	default:
-		slogLevel = slog.LevelInfo
+		return slog.LevelInfo
 	}
+}
+
+// SetLevel changes the minimum level of the global logger. It takes effect immediately,
+// including for loggers derived with WithComponent and similar helpers.
+func SetLevel(name string) {
+	level.Set(parseLevel(name))
+}
+
+// InitializeLogger initializes the global logger with the specified level and format.
+func InitializeLogger(levelName string, jsonFormat bool) {
+	SetLevel(levelName)
 
 	opts := &slog.HandlerOptions{
-		Level: slogLevel,
+		Level: &level,
 		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
 			// Customize timestamp format.
 			if a.Key == slog.TimeKey {
@@ -63,13 +124,20 @@ func InitializeLogger(level string, jsonFormat bool) {
 		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
 
-	DefaultLogger = slog.New(handler)
+	DefaultLogger = slog.New(contextHandler{scrubHandler{handler}})
 	slog.SetDefault(DefaultLogger)
 }
 
-// WithContext returns a logger with context values.
-func WithContext(_ context.Context) *slog.Logger {
-	return DefaultLogger.With()
+// WithContext returns a logger with context values, such as the active trace and span IDs.
+func WithContext(ctx context.Context) *slog.Logger {
+	attrs := attrsFromContext(ctx)
+
+	args := make([]any, 0, len(attrs))
+	for _, attr := range attrs {
+		args = append(args, attr)
+	}
+
+	return DefaultLogger.With(args...)
 }
 
 // WithComponent returns a logger with a component field.
@@ -89,6 +157,11 @@ func WithCommand(command string) *slog.Logger {
 
 // LogError logs a BotError with appropriate structured fields.
 func LogError(logger *slog.Logger, err error, message string) {
+	LogErrorContext(context.Background(), logger, err, message)
+}
+
+// LogErrorContext logs a BotError with appropriate structured fields and the attributes of ctx.
+func LogErrorContext(ctx context.Context, logger *slog.Logger, err error, message string) {
 	var botErr *botErrors.BotError
 	if errors.As(err, &botErr) {
 		attrs := []slog.Attr{
@@ -108,9 +181,9 @@ func LogError(logger *slog.Logger, err error, message string) {
 			attrs = append(attrs, slog.Any(key, value))
 		}
 
-		logger.LogAttrs(context.Background(), slog.LevelError, message, attrs...)
+		logger.LogAttrs(ctx, slog.LevelError, message, attrs...)
 	} else {
-		logger.Error(message, "error", err)
+		logger.ErrorContext(ctx, message, "error", err)
 	}
 }
 
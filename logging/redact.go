@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redacted replaces scrubbed secrets in log output.
+const redacted = "[redacted]"
+
+// tokenPatterns match credentials that must never reach the logs, even when they were
+// never registered: Discord bot tokens and other dot-separated tokens such as JWTs,
+// the credentials of Authorization headers, and the token part of webhook URLs.
+var tokenPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[A-Za-z0-9_-]{24,}\.[A-Za-z0-9_-]{6,}\.[A-Za-z0-9_-]{27,}`), redacted},
+	{regexp.MustCompile(`(?i)\b(Bot|Bearer|Basic) [A-Za-z0-9._~+/=-]{20,}`), "${1} " + redacted},
+	{regexp.MustCompile(`(/api/(?:v\d+/)?webhooks/\d+/)[A-Za-z0-9_-]+`), "${1}" + redacted},
+}
+
+// secrets holds the values registered with RegisterSecret.
+var secrets struct {
+	sync.RWMutex
+	values []string
+}
+
+// RegisterSecret makes the scrubber replace every occurrence of value, whatever it looks
+// like. Register the secrets of the configuration once they are loaded.
+func RegisterSecret(value string) {
+	if value == "" {
+		return
+	}
+
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	for _, known := range secrets.values {
+		if known == value {
+			return
+		}
+	}
+
+	secrets.values = append(secrets.values, value)
+}
+
+// Scrub returns text with registered secrets and token-looking strings replaced by "[redacted]".
+func Scrub(text string) string {
+	secrets.RLock()
+	for _, value := range secrets.values {
+		text = strings.ReplaceAll(text, value, redacted)
+	}
+	secrets.RUnlock()
+
+	for _, token := range tokenPatterns {
+		text = token.pattern.ReplaceAllString(text, token.replacement)
+	}
+
+	return text
+}
+
+// scrubHandler scrubs the message and every attribute of each record before passing it on,
+// including errors such as the cause of a BotError.
+type scrubHandler struct {
+	slog.Handler
+}
+
+// Handle scrubs the record.
+func (h scrubHandler) Handle(ctx context.Context, record slog.Record) error {
+	scrubbed := slog.NewRecord(record.Time, record.Level, Scrub(record.Message), record.PC)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		scrubbed.AddAttrs(scrubAttr(attr))
+		return true
+	})
+
+	return h.Handler.Handle(ctx, scrubbed)
+}
+
+// WithAttrs returns a scrubHandler whose wrapped handler has the scrubbed attributes.
+func (h scrubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		scrubbed[i] = scrubAttr(attr)
+	}
+
+	return scrubHandler{h.Handler.WithAttrs(scrubbed)}
+}
+
+// WithGroup returns a scrubHandler whose wrapped handler has the group.
+func (h scrubHandler) WithGroup(name string) slog.Handler {
+	return scrubHandler{h.Handler.WithGroup(name)}
+}
+
+// scrubAttr scrubs the value of attr. Values that are not strings keep their type
+// unless their text contains a secret, in which case they are replaced by the scrubbed text.
+func scrubAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(Scrub(attr.Value.String()))
+	case slog.KindGroup:
+		group := attr.Value.Group()
+
+		scrubbed := make([]slog.Attr, len(group))
+		for i, member := range group {
+			scrubbed[i] = scrubAttr(member)
+		}
+
+		attr.Value = slog.GroupValue(scrubbed...)
+	case slog.KindAny:
+		var text string
+
+		switch v := attr.Value.Any().(type) {
+		case error:
+			text = v.Error()
+		default:
+			text = fmt.Sprint(v)
+		}
+
+		if scrubbed := Scrub(text); scrubbed != text {
+			attr.Value = slog.StringValue(scrubbed)
+		}
+	}
+
+	return attr
+}
@@ -2,36 +2,148 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/dunamismax/discogo/alerting"
 	"github.com/dunamismax/discogo/config"
 	"github.com/dunamismax/discogo/discord"
+	"github.com/dunamismax/discogo/errors"
 	"github.com/dunamismax/discogo/logging"
 	"github.com/dunamismax/discogo/metrics"
+	"github.com/dunamismax/discogo/storage"
+	"github.com/dunamismax/discogo/tracing"
 )
 
 func main() {
+	configFile := flag.String("config", "", "path to a YAML, TOML or JSON configuration file (default $CONFIG_FILE)")
+	profile := flag.String("profile", "", "configuration file profile to apply (default $CONFIG_PROFILE)")
+	envFile := flag.String("env-file", config.DefaultEnvFile, "path to the .env file")
+	flag.Parse()
+
 	// Load configuration.
-	cfg, err := config.Load()
+	configOptions := config.Options{
+		ConfigFile: *configFile,
+		Profile:    *profile,
+		EnvFile:    *envFile,
+	}
+
+	cfg, err := config.LoadWithOptions(configOptions)
 	if err != nil {
 		logging.Error("Failed to load configuration", "error", err)
 		os.Exit(1)
 	}
 
-	// Validate configuration.
-	if err := cfg.Validate(); err != nil {
-		logging.Error("Invalid configuration", "error", err)
+	// Scrub the token from every log record, whatever shape it appears in.
+	logging.RegisterSecret(cfg.DiscordToken.Reveal())
+
+	// The migrate, export and import commands only work on the storage, so they do not need
+	// the settings to connect to Discord.
+	command := flag.Arg(0)
+	offline := command == "migrate" || command == "export" || command == "import"
+
+	validate := cfg.Validate
+	if offline {
+		validate = cfg.ValidateOffline
+	}
+
+	// Validate configuration, reporting every problem before exiting.
+	if err := validate(); err != nil {
+		problems := config.Problems(err)
+		logging.Error("Invalid configuration", "problems", len(problems))
+
+		for _, problem := range problems {
+			logging.Error("Configuration problem", "setting", problem.Key, "value", problem.Value,
+				"problem", problem.Message)
+		}
+
 		os.Exit(1)
 	}
 
 	// Initialize logging.
 	logging.InitializeLogger(cfg.LogLevel, cfg.JSONLogging)
 
+	// Manage storage migrations without connecting to Discord.
+	if command == "migrate" {
+		os.Exit(runMigrate(cfg, flag.Args()[1:]))
+	}
+
+	// Export and import guild data without connecting to Discord.
+	if command == "export" || command == "import" {
+		os.Exit(runArchive(cfg, command, flag.Args()[1:]))
+	}
+
+	// Catch SIGHUP before connecting, so one sent during startup is not fatal; it reloads
+	// the configuration once the bot is running.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
 	// Initialize metrics.
-	metrics.Initialize()
+	metrics.Initialize().SetPerGuildTracking(cfg.MetricsPerGuild)
+
+	var svc services
+
+	// Correlate log records with trace spans.
+	logging.RegisterContextAttrs(tracing.LogAttrs)
+
+	// Start exporting traces if enabled.
+	if cfg.TracingEnabled {
+		svc.tracer, err = tracing.NewProvider(tracing.Config{
+			ServiceName: cfg.BotName,
+			Endpoint:    cfg.TracingEndpoint,
+			SampleRatio: cfg.TracingSampleRatio,
+		})
+		if err != nil {
+			logging.Error("Failed to start tracing", "error", err)
+			os.Exit(1)
+		}
+
+		tracing.SetProvider(svc.tracer)
+		logging.Info("Tracing enabled", "endpoint", cfg.TracingEndpoint, "sample_ratio", cfg.TracingSampleRatio)
+	}
+
+	// Restore lifetime metrics and start periodic snapshots.
+	if cfg.MetricsPersist {
+		metricsPersister := metrics.NewPersister(metrics.NewFileStore(cfg.MetricsStateFile), cfg.MetricsSnapshotInterval)
+		if err := metricsPersister.Restore(); err != nil {
+			logging.Warn("Failed to restore lifetime metrics, starting from zero", "error", err)
+		}
+
+		metricsPersister.Start()
+		svc.metricsPersister = metricsPersister
+	}
+
+	// Start pushing metrics to StatsD if configured.
+	if cfg.StatsDAddr != "" {
+		svc.statsd, err = metrics.NewStatsDExporter(metrics.StatsDConfig{
+			Addr:      cfg.StatsDAddr,
+			Prefix:    cfg.StatsDPrefix,
+			Interval:  cfg.StatsDInterval,
+			Tags:      cfg.StatsDTags,
+			DogStatsD: cfg.StatsDDogStatsD,
+		})
+		if err != nil {
+			logging.Error("Failed to start StatsD exporter", "error", err)
+			os.Exit(1)
+		}
+
+		svc.statsd.Start()
+	}
+
+	// Start the metrics endpoint if configured.
+	if cfg.MetricsAddr != "" {
+		metricsServer := metrics.NewServer(cfg.MetricsAddr)
+		if err := metricsServer.Start(); err != nil {
+			logging.Error("Failed to start metrics server", "error", err)
+			os.Exit(1)
+		}
+
+		svc.metricsServer = metricsServer
+	}
 
 	// Log startup information.
 	logging.LogStartup(cfg.BotName, cfg.CommandPrefix, cfg.LogLevel, cfg.DebugMode)
@@ -40,8 +152,17 @@ func main() {
 		logging.Debug("Debug mode enabled")
 	}
 
+	// Open the storage persistent features keep their data in.
+	svc.store, err = storage.Open(storage.Config{Backend: cfg.StorageBackend, Path: cfg.StoragePath})
+	if err != nil {
+		logging.Error("Failed to open storage", "error", err)
+		os.Exit(1)
+	}
+
+	logging.Info("Storage opened", "backend", cfg.StorageBackend, "path", cfg.StoragePath)
+
 	// Create Discord bot.
-	bot, err := discord.NewBot(cfg)
+	bot, err := discord.NewBot(cfg, svc.store)
 	if err != nil {
 		logging.Error("Failed to create Discord bot", "error", err)
 		os.Exit(1)
@@ -53,11 +174,119 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Allow owners to reload the configuration with !reload.
+	reload := newReloader(configOptions, bot)
+	bot.SetReloadFunc(reload)
+
+	// Start alerting if enabled.
+	if cfg.AlertsEnabled {
+		svc.alerts = alerting.NewManager(bot, alertRules(cfg), alerting.Options{
+			Interval:      cfg.AlertInterval,
+			Cooldown:      cfg.AlertCooldown,
+			OnStateChange: bot.SetAlertFiring,
+		})
+		svc.alerts.Start()
+	}
+
 	// Print usage instructions.
 	printUsageInstructions(cfg.CommandPrefix)
 
-	// Setup graceful shutdown.
-	gracefulShutdown(bot, cfg.ShutdownTimeout)
+	// Reload on SIGHUP and shut down gracefully on SIGINT or SIGTERM.
+	waitForSignals(signals, reload)
+	gracefulShutdown(bot, &svc, bot.Config().ShutdownTimeout)
+}
+
+// newReloader returns a function that loads and validates the configuration again and
+// applies the settings that can change at runtime. An invalid configuration is rejected
+// as a whole, so the running configuration is never partially updated.
+func newReloader(options config.Options, bot *discord.Bot) discord.ReloadFunc {
+	var mutex sync.Mutex
+
+	return func() ([]config.Change, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		logger := logging.WithComponent("config")
+
+		loaded, err := config.LoadWithOptions(options)
+		if err == nil {
+			err = loaded.Validate()
+		}
+
+		if err != nil {
+			logger.Error("Configuration reload rejected", "error", err)
+			return nil, err
+		}
+
+		applied, changes := config.Reconcile(bot.Config(), loaded)
+
+		logging.SetLevel(applied.LogLevel)
+		metrics.Get().SetPerGuildTracking(applied.MetricsPerGuild)
+		bot.ApplyConfig(applied)
+
+		for _, change := range changes {
+			if change.RequiresRestart {
+				logger.Warn("Setting changed but requires a restart", "setting", change.Key)
+			} else {
+				logger.Info("Setting changed", "setting", change.Key, "old", change.Old, "new", change.New)
+			}
+		}
+
+		logger.Info("Configuration reloaded", "changes", len(changes))
+
+		return changes, nil
+	}
+}
+
+// waitForSignals blocks until the process is asked to stop, reloading the configuration on every
+// SIGHUP. sigChan already receives SIGHUP; the stop signals are added here, so they still end the
+// process right away during startup.
+func waitForSignals(sigChan chan os.Signal, reload discord.ReloadFunc) {
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+
+	logging.Info("Bot is running. Press Ctrl+C to stop, or send SIGHUP to reload the configuration.")
+
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			signal.Stop(sigChan)
+			logging.Info("Received signal, initiating graceful shutdown", "signal", sig.String())
+
+			return
+		}
+
+		logging.Info("Received SIGHUP, reloading configuration")
+
+		_, _ = reload() // Problems are logged by the reloader.
+	}
+}
+
+// services holds the optional background services that need to be stopped on shutdown.
+type services struct {
+	metricsServer    *metrics.Server
+	metricsPersister *metrics.Persister
+	statsd           *metrics.StatsDExporter
+	tracer           *tracing.Provider
+	alerts           *alerting.Manager
+	store            storage.Store
+}
+
+// alertRules builds the alert rules enabled by the configuration. A zero threshold disables a rule.
+func alertRules(cfg *config.Config) []alerting.Rule {
+	var rules []alerting.Rule
+
+	if cfg.AlertFailureRate > 0 {
+		rules = append(rules, alerting.CommandFailureRate(cfg.AlertFailureRate, cfg.AlertWindow, int64(cfg.AlertMinCommands)))
+	}
+
+	if cfg.AlertRateLimitThreshold > 0 {
+		rules = append(rules, alerting.ErrorSpike(errors.ErrorTypeRateLimit, int64(cfg.AlertRateLimitThreshold), cfg.AlertWindow))
+	}
+
+	if cfg.AlertGatewayTimeout > 0 {
+		rules = append(rules, alerting.GatewayDisconnected(cfg.AlertGatewayTimeout))
+	}
+
+	return rules
 }
 
 func printUsageInstructions(prefix string) {
@@ -66,23 +295,13 @@ func printUsageInstructions(prefix string) {
 	logger.Info("Help", "command", prefix+"help")
 	logger.Info("Statistics", "command", prefix+"stats")
 	logger.Info("Ping", "command", prefix+"ping")
+	logger.Info("Reload configuration (owners)", "command", prefix+"reload")
+	logger.Info("Show configuration (owners)", "command", prefix+"config show")
 	logger.Info("==========================")
 }
 
 // gracefulShutdown handles graceful shutdown with timeout.
-func gracefulShutdown(bot *discord.Bot, timeout time.Duration) {
-	// Create a channel to receive OS signals.
-	sigChan := make(chan os.Signal, 1)
-
-	// Register the channel to receive specific signals.
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
-
-	logging.Info("Bot is running. Press Ctrl+C to stop.")
-
-	// Wait for a signal.
-	sig := <-sigChan
-	logging.Info("Received signal, initiating graceful shutdown", "signal", sig.String())
-
+func gracefulShutdown(bot *discord.Bot, svc *services, timeout time.Duration) {
 	// Create a context with timeout for shutdown.
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -94,6 +313,11 @@ func gracefulShutdown(bot *discord.Bot, timeout time.Duration) {
 	go func() {
 		defer func() { done <- true }()
 
+		// Stop alerting first so the planned disconnect does not raise an alert.
+		if svc.alerts != nil {
+			svc.alerts.Stop()
+		}
+
 		logging.Info("Stopping Discord bot...")
 
 		if err := bot.Stop(); err != nil {
@@ -102,6 +326,37 @@ func gracefulShutdown(bot *discord.Bot, timeout time.Duration) {
 			logging.Info("Discord bot stopped successfully")
 		}
 
+		// Close storage once the bot no longer handles commands.
+		if svc.store != nil {
+			if err := svc.store.Close(); err != nil {
+				logging.Error("Error closing storage", "error", err)
+			}
+		}
+
+		if svc.metricsServer != nil {
+			if err := svc.metricsServer.Shutdown(ctx); err != nil {
+				logging.Error("Error stopping metrics server", "error", err)
+			}
+		}
+
+		if svc.statsd != nil {
+			if err := svc.statsd.Stop(); err != nil {
+				logging.Error("Error flushing StatsD metrics", "error", err)
+			}
+		}
+
+		if svc.metricsPersister != nil {
+			if err := svc.metricsPersister.Stop(); err != nil {
+				logging.Error("Error saving final metrics snapshot", "error", err)
+			}
+		}
+
+		if svc.tracer != nil {
+			if err := svc.tracer.Shutdown(ctx); err != nil {
+				logging.Error("Error flushing traces", "error", err)
+			}
+		}
+
 		// Log final metrics.
 		metricsSummary := metrics.Get().GetSummary()
 		logging.Info("Final metrics", "commands_total", metricsSummary.CommandsTotal)
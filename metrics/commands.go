@@ -0,0 +1,264 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// commandCounters tracks executions and latencies of a single command.
+type commandCounters struct {
+	total    int64
+	failed   int64
+	duration *Histogram
+}
+
+func newCommandCounters() *commandCounters {
+	return &commandCounters{duration: NewHistogram(DefaultDurationBuckets)}
+}
+
+// maxTrackedGuilds caps the guilds with a per-guild breakdown, since every guild adds a
+// histogram per command it uses. Commands in further guilds only count towards the totals.
+const maxTrackedGuilds = 1000
+
+// commandRegistry tracks per-command metrics, optionally broken down by guild.
+type commandRegistry struct {
+	commands map[string]*commandCounters
+	guilds   map[string]map[string]*commandCounters
+	perGuild bool
+	mutex    sync.RWMutex
+}
+
+func newCommandRegistry() *commandRegistry {
+	return &commandRegistry{
+		commands: make(map[string]*commandCounters),
+		guilds:   make(map[string]map[string]*commandCounters),
+	}
+}
+
+// record records a single command execution.
+func (cr *commandRegistry) record(command, guildID string, successful bool, duration time.Duration) {
+	cr.mutex.Lock()
+
+	counters := cr.commands[command]
+	if counters == nil {
+		counters = newCommandCounters()
+		cr.commands[command] = counters
+	}
+
+	counters.total++
+	if !successful {
+		counters.failed++
+	}
+
+	var guildCounters *commandCounters
+
+	if cr.perGuild && guildID != "" {
+		guild := cr.guilds[guildID]
+		if guild == nil && len(cr.guilds) < maxTrackedGuilds {
+			guild = make(map[string]*commandCounters)
+			cr.guilds[guildID] = guild
+		}
+
+		if guild != nil {
+			guildCounters = guild[command]
+			if guildCounters == nil {
+				guildCounters = newCommandCounters()
+				guild[command] = guildCounters
+			}
+
+			guildCounters.total++
+			if !successful {
+				guildCounters.failed++
+			}
+		}
+	}
+
+	cr.mutex.Unlock()
+
+	counters.duration.Observe(duration.Seconds())
+
+	if guildCounters != nil {
+		guildCounters.duration.Observe(duration.Seconds())
+	}
+}
+
+// CommandStats holds the statistics of a single command.
+type CommandStats struct {
+	Name            string            `json:"name"`
+	GuildID         string            `json:"guild_id,omitempty"`
+	Total           int64             `json:"total"`
+	Successful      int64             `json:"successful"`
+	Failed          int64             `json:"failed"`
+	SuccessRate     float64           `json:"success_rate_percent"`
+	AverageDuration time.Duration     `json:"average_duration_ns"`
+	P50             time.Duration     `json:"p50_ns"`
+	P95             time.Duration     `json:"p95_ns"`
+	P99             time.Duration     `json:"p99_ns"`
+	Duration        HistogramSnapshot `json:"duration_seconds"`
+}
+
+func (cc *commandCounters) stats(name, guildID string) CommandStats {
+	snapshot := cc.duration.Snapshot()
+
+	stats := CommandStats{
+		Name:            name,
+		GuildID:         guildID,
+		Total:           cc.total,
+		Successful:      cc.total - cc.failed,
+		Failed:          cc.failed,
+		AverageDuration: secondsToDuration(snapshot.Mean()),
+		P50:             secondsToDuration(snapshot.Quantile(0.50)),
+		P95:             secondsToDuration(snapshot.Quantile(0.95)),
+		P99:             secondsToDuration(snapshot.Quantile(0.99)),
+		Duration:        snapshot,
+	}
+
+	if stats.Total > 0 {
+		stats.SuccessRate = (float64(stats.Successful) / float64(stats.Total)) * 100.0
+	}
+
+	return stats
+}
+
+// all returns the statistics of every command sorted by total executions, most used first.
+func (cr *commandRegistry) all() []CommandStats {
+	cr.mutex.RLock()
+	defer cr.mutex.RUnlock()
+
+	stats := make([]CommandStats, 0, len(cr.commands))
+	for name, counters := range cr.commands {
+		stats = append(stats, counters.stats(name, ""))
+	}
+
+	sortCommandStats(stats)
+
+	return stats
+}
+
+// allGuilds returns the per-guild statistics of every command.
+func (cr *commandRegistry) allGuilds() []CommandStats {
+	cr.mutex.RLock()
+	defer cr.mutex.RUnlock()
+
+	stats := make([]CommandStats, 0)
+
+	for guildID, commands := range cr.guilds {
+		for name, counters := range commands {
+			stats = append(stats, counters.stats(name, guildID))
+		}
+	}
+
+	sortCommandStats(stats)
+
+	return stats
+}
+
+// get returns the statistics of a single command, or of the command within a guild
+// when guildID is not empty.
+func (cr *commandRegistry) get(command, guildID string) (CommandStats, bool) {
+	cr.mutex.RLock()
+	defer cr.mutex.RUnlock()
+
+	counters := cr.commands[command]
+	if guildID != "" {
+		counters = cr.guilds[guildID][command]
+	}
+
+	if counters == nil {
+		return CommandStats{}, false
+	}
+
+	return counters.stats(command, guildID), true
+}
+
+func sortCommandStats(stats []CommandStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+
+		if stats[i].Name != stats[j].Name {
+			return stats[i].Name < stats[j].Name
+		}
+
+		return stats[i].GuildID < stats[j].GuildID
+	})
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// SetPerGuildTracking enables or disables the per-guild breakdown of command metrics.
+// At most maxTrackedGuilds guilds are broken down; disabling it drops the breakdown.
+func (m *Metrics) SetPerGuildTracking(enabled bool) {
+	m.commands.mutex.Lock()
+	defer m.commands.mutex.Unlock()
+
+	m.commands.perGuild = enabled
+	if !enabled {
+		m.commands.guilds = make(map[string]map[string]*commandCounters)
+	}
+}
+
+// IncrementCommand records the execution of a named command, updating both the
+// global counters and the per-command counters and latency histogram.
+func (m *Metrics) IncrementCommand(command, guildID string, successful bool, duration time.Duration) {
+	m.IncrementCommands(successful)
+	m.commands.record(command, guildID, successful, duration)
+
+	if exporter := m.statsd.Load(); exporter != nil {
+		tags := []label{{"command", command}, {"result", resultLabel(successful)}}
+
+		m.commands.mutex.RLock()
+		perGuild := m.commands.perGuild
+		m.commands.mutex.RUnlock()
+
+		if perGuild && guildID != "" {
+			tags = append(tags, label{"guild", guildID})
+		}
+
+		exporter.recordTiming("command.duration", duration, tags...)
+	}
+}
+
+// GetCommandStats returns the statistics of a single command.
+func (m *Metrics) GetCommandStats(command string) (CommandStats, bool) {
+	return m.commands.get(command, "")
+}
+
+// GetGuildCommandStats returns the statistics of a single command within a guild.
+// It only reports data when per-guild tracking is enabled.
+func (m *Metrics) GetGuildCommandStats(guildID, command string) (CommandStats, bool) {
+	return m.commands.get(command, guildID)
+}
+
+// GetCommandsStats returns the statistics of every command, most used first.
+func (m *Metrics) GetCommandsStats() []CommandStats {
+	return m.commands.all()
+}
+
+// GetTopCommands returns up to limit commands, most used first.
+func (m *Metrics) GetTopCommands(limit int) []CommandStats {
+	stats := m.commands.all()
+	if limit >= 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+
+	return stats
+}
+
+// RecordCommandExecution is a convenience function to record a named command execution.
+func RecordCommandExecution(command, guildID string, successful bool, duration time.Duration) {
+	Get().IncrementCommand(command, guildID, successful, duration)
+}
+
+// resultLabel returns the result label value for an outcome.
+func resultLabel(successful bool) string {
+	if successful {
+		return "success"
+	}
+
+	return "failure"
+}
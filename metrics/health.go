@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// gatewaySampleCapacity is how many recent heartbeat latency samples are kept for percentiles.
+const gatewaySampleCapacity = 240
+
+// latencySamples keeps the most recent latency samples in a ring.
+type latencySamples struct {
+	samples []time.Duration
+	next    int
+	full    bool
+	count   int64
+	sum     time.Duration
+	mutex   sync.Mutex
+}
+
+func newLatencySamples(capacity int) *latencySamples {
+	return &latencySamples{samples: make([]time.Duration, capacity)}
+}
+
+func (ls *latencySamples) add(latency time.Duration) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	ls.samples[ls.next] = latency
+	ls.next = (ls.next + 1) % len(ls.samples)
+
+	if ls.next == 0 {
+		ls.full = true
+	}
+
+	ls.count++
+	ls.sum += latency
+}
+
+// LatencySummary describes a latency distribution. Percentiles are computed over recent samples.
+type LatencySummary struct {
+	Last    time.Duration `json:"last_ns"`
+	P50     time.Duration `json:"p50_ns"`
+	P95     time.Duration `json:"p95_ns"`
+	P99     time.Duration `json:"p99_ns"`
+	Samples int           `json:"samples"`
+	Count   int64         `json:"count"`
+	Sum     time.Duration `json:"sum_ns"`
+}
+
+func (ls *latencySamples) summary() LatencySummary {
+	ls.mutex.Lock()
+
+	size := ls.next
+	if ls.full {
+		size = len(ls.samples)
+	}
+
+	sorted := make([]time.Duration, size)
+	copy(sorted, ls.samples[:size])
+
+	summary := LatencySummary{Samples: size, Count: ls.count, Sum: ls.sum}
+	if size > 0 {
+		summary.Last = ls.samples[(ls.next-1+len(ls.samples))%len(ls.samples)]
+	}
+
+	ls.mutex.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	summary.P50 = durationPercentile(sorted, 0.50)
+	summary.P95 = durationPercentile(sorted, 0.95)
+	summary.P99 = durationPercentile(sorted, 0.99)
+
+	return summary
+}
+
+// durationPercentile returns the nearest-rank percentile of sorted durations.
+func durationPercentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	index := int(q*float64(len(sorted))+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+
+	return sorted[index]
+}
+
+// RuntimeSummary holds Go runtime health figures.
+type RuntimeSummary struct {
+	Goroutines     int           `json:"goroutines"`
+	HeapAllocBytes uint64        `json:"heap_alloc_bytes"`
+	HeapInuseBytes uint64        `json:"heap_inuse_bytes"`
+	SysBytes       uint64        `json:"sys_bytes"`
+	NumGC          uint32        `json:"num_gc"`
+	GCPauseTotal   time.Duration `json:"gc_pause_total_ns"`
+	GCPauseLast    time.Duration `json:"gc_pause_last_ns"`
+	GCPauseP50     time.Duration `json:"gc_pause_p50_ns"`
+	GCPauseP99     time.Duration `json:"gc_pause_p99_ns"`
+	GCPauseMax     time.Duration `json:"gc_pause_max_ns"`
+	GCCPUFraction  float64       `json:"gc_cpu_fraction"`
+}
+
+// ReadRuntimeSummary reads the current Go runtime health figures.
+// GC pause percentiles cover the most recent (up to 256) collections.
+func ReadRuntimeSummary() RuntimeSummary {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	return runtimeSummary(&memStats)
+}
+
+func runtimeSummary(memStats *runtime.MemStats) RuntimeSummary {
+	summary := RuntimeSummary{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: memStats.HeapAlloc,
+		HeapInuseBytes: memStats.HeapInuse,
+		SysBytes:       memStats.Sys,
+		NumGC:          memStats.NumGC,
+		GCPauseTotal:   time.Duration(memStats.PauseTotalNs),
+		GCCPUFraction:  memStats.GCCPUFraction,
+	}
+
+	recent := int(memStats.NumGC)
+	if recent > len(memStats.PauseNs) {
+		recent = len(memStats.PauseNs)
+	}
+
+	if recent == 0 {
+		return summary
+	}
+
+	summary.GCPauseLast = time.Duration(memStats.PauseNs[(memStats.NumGC+255)%256])
+
+	pauses := make([]time.Duration, 0, recent)
+	for i := 0; i < recent; i++ {
+		pauses = append(pauses, time.Duration(memStats.PauseNs[i]))
+	}
+
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i] < pauses[j] })
+
+	summary.GCPauseP50 = durationPercentile(pauses, 0.50)
+	summary.GCPauseP99 = durationPercentile(pauses, 0.99)
+	summary.GCPauseMax = pauses[len(pauses)-1]
+
+	return summary
+}
+
+// GetGatewayLatencySummary returns the gateway heartbeat latency distribution.
+func (m *Metrics) GetGatewayLatencySummary() LatencySummary {
+	return m.gatewayLatency.summary()
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// DefaultDurationBuckets are the histogram bucket upper bounds, in seconds, used for command latencies.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observations into fixed buckets.
+type Histogram struct {
+	upperBounds []float64
+	counts      []uint64 // per bucket, not cumulative; the last entry is the +Inf bucket.
+	count       uint64
+	sum         float64
+	mutex       sync.Mutex
+}
+
+// HistogramSnapshot is a point-in-time copy of a histogram.
+type HistogramSnapshot struct {
+	// UpperBounds are the bucket upper bounds, excluding +Inf.
+	UpperBounds []float64 `json:"upper_bounds"`
+	// CumulativeCounts holds the number of observations less than or equal to each
+	// upper bound, followed by the total count for the +Inf bucket.
+	CumulativeCounts []uint64 `json:"cumulative_counts"`
+	Count            uint64   `json:"count"`
+	Sum              float64  `json:"sum"`
+}
+
+// NewHistogram creates a histogram with the given bucket upper bounds.
+// The bounds are sorted; a +Inf bucket is always implied.
+func NewHistogram(upperBounds []float64) *Histogram {
+	bounds := make([]float64, 0, len(upperBounds))
+	for _, bound := range upperBounds {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+
+	sort.Float64s(bounds)
+
+	return &Histogram{
+		upperBounds: bounds,
+		counts:      make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe records a single observation.
+func (h *Histogram) Observe(value float64) {
+	index := sort.SearchFloat64s(h.upperBounds, value)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.counts[index]++
+	h.count++
+	h.sum += value
+}
+
+// Snapshot returns a consistent copy of the histogram state.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	cumulative := make([]uint64, len(h.counts))
+
+	var running uint64
+	for i, count := range h.counts {
+		running += count
+		cumulative[i] = running
+	}
+
+	return HistogramSnapshot{
+		UpperBounds:      append([]float64(nil), h.upperBounds...),
+		CumulativeCounts: cumulative,
+		Count:            h.count,
+		Sum:              h.sum,
+	}
+}
+
+// Mean returns the average of all observations.
+func (hs HistogramSnapshot) Mean() float64 {
+	if hs.Count == 0 {
+		return 0.0
+	}
+
+	return hs.Sum / float64(hs.Count)
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) by linear interpolation
+// within the bucket that contains it, like Prometheus' histogram_quantile.
+// Observations in the +Inf bucket are reported as the highest finite bound.
+func (hs HistogramSnapshot) Quantile(q float64) float64 {
+	if hs.Count == 0 || len(hs.UpperBounds) == 0 {
+		return 0.0
+	}
+
+	q = math.Max(0, math.Min(1, q))
+	rank := q * float64(hs.Count)
+
+	index := sort.Search(len(hs.CumulativeCounts), func(i int) bool {
+		return float64(hs.CumulativeCounts[i]) >= rank
+	})
+
+	if index >= len(hs.UpperBounds) {
+		return hs.UpperBounds[len(hs.UpperBounds)-1]
+	}
+
+	lowerBound := 0.0
+	lowerCount := uint64(0)
+
+	if index > 0 {
+		lowerBound = hs.UpperBounds[index-1]
+		lowerCount = hs.CumulativeCounts[index-1]
+	}
+
+	bucketCount := hs.CumulativeCounts[index] - lowerCount
+	if bucketCount == 0 {
+		return hs.UpperBounds[index]
+	}
+
+	fraction := (rank - float64(lowerCount)) / float64(bucketCount)
+
+	return lowerBound + (hs.UpperBounds[index]-lowerBound)*fraction
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// HistoryRetention is how far back the per-minute history reaches.
+const HistoryRetention = 24 * time.Hour
+
+// historySlot accumulates the activity of one minute.
+type historySlot struct {
+	minute         int64 // unix minute this slot currently holds.
+	commands       int64
+	commandsFailed int64
+	errors         int64
+	apiRequests    int64
+	apiLatencySum  int64 // in milliseconds.
+}
+
+// history is a ring of per-minute slots covering HistoryRetention.
+type history struct {
+	slots []historySlot
+	mutex sync.Mutex
+}
+
+// HistoryPoint is the activity recorded during one minute.
+type HistoryPoint struct {
+	Time           time.Time `json:"time"`
+	Commands       int64     `json:"commands"`
+	CommandsFailed int64     `json:"commands_failed"`
+	Errors         int64     `json:"errors"`
+	APIRequests    int64     `json:"api_requests"`
+	AverageLatency float64   `json:"average_latency_ms"`
+}
+
+func newHistory() *history {
+	return &history{slots: make([]historySlot, int(HistoryRetention/time.Minute))}
+}
+
+// slot returns the slot for the minute of timestamp, resetting it if it holds an older minute.
+// The caller must hold the mutex.
+func (h *history) slot(timestamp time.Time) *historySlot {
+	minute := timestamp.Unix() / 60
+	size := int64(len(h.slots))
+	s := &h.slots[((minute%size)+size)%size]
+
+	if s.minute != minute {
+		*s = historySlot{minute: minute}
+	}
+
+	return s
+}
+
+func (h *history) recordCommand(timestamp time.Time, successful bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s := h.slot(timestamp)
+
+	s.commands++
+	if !successful {
+		s.commandsFailed++
+	}
+}
+
+func (h *history) recordAPIRequest(timestamp time.Time, responseTimeMs int64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s := h.slot(timestamp)
+	s.apiRequests++
+	s.apiLatencySum += responseTimeMs
+}
+
+func (h *history) recordError(timestamp time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.slot(timestamp).errors++
+}
+
+// points returns one point per minute for the window ending at now, oldest first.
+// Minutes without activity are reported as zero.
+func (h *history) points(now time.Time, window time.Duration) []HistoryPoint {
+	if window > HistoryRetention {
+		window = HistoryRetention
+	}
+
+	count := int64(window / time.Minute)
+	current := now.Unix() / 60
+	size := int64(len(h.slots))
+	points := make([]HistoryPoint, 0, count)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for minute := current - count + 1; minute <= current; minute++ {
+		point := HistoryPoint{Time: time.Unix(minute*60, 0)}
+
+		s := h.slots[((minute%size)+size)%size]
+		if s.minute == minute {
+			point.Commands = s.commands
+			point.CommandsFailed = s.commandsFailed
+			point.Errors = s.errors
+			point.APIRequests = s.apiRequests
+
+			if s.apiRequests > 0 {
+				point.AverageLatency = float64(s.apiLatencySum) / float64(s.apiRequests)
+			}
+		}
+
+		points = append(points, point)
+	}
+
+	return points
+}
+
+// GetHistory returns the per-minute activity over the last window, oldest first.
+// The window is capped at HistoryRetention.
+func (m *Metrics) GetHistory(window time.Duration) []HistoryPoint {
+	return m.history.points(time.Now(), window)
+}
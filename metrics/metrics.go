@@ -4,6 +4,7 @@ package metrics
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	botErrors "github.com/dunamismax/discogo/errors"
@@ -15,13 +16,11 @@ type Metrics struct {
 	CommandsTotal      int64
 	CommandsSuccessful int64
 	CommandsFailed     int64
-	CommandsPerSecond  float64
 
 	// API metrics.
 	APIRequestsTotal      int64
 	APIRequestsSuccessful int64
 	APIRequestsFailed     int64
-	APIRequestsPerSecond  float64
 	APIResponseTimeSum    int64 // in milliseconds.
 	APIResponseCount      int64
 
@@ -29,74 +28,31 @@ type Metrics struct {
 	ErrorsByType map[botErrors.ErrorType]int64
 
 	// Bot metrics.
-	BotStartTime time.Time
+	BotStartTime   time.Time
+	GatewayLatency time.Duration
 
-	// Rate tracking.
-	commandWindow *RateWindow
-	apiWindow     *RateWindow
-	mutex         sync.RWMutex
-}
-
-// RateWindow tracks events within a time window for rate calculations.
-type RateWindow struct {
-	events []time.Time
-	window time.Duration
-	mutex  sync.Mutex
-}
-
-// NewRateWindow creates a new rate tracking window.
-func NewRateWindow(window time.Duration) *RateWindow {
-	return &RateWindow{
-		events: make([]time.Time, 0),
-		window: window,
-	}
-}
+	// Gateway connection state and when it last changed.
+	gatewayConnected   bool
+	gatewayStatusSince time.Time
 
-// Add records an event timestamp.
-func (rw *RateWindow) Add(timestamp time.Time) {
-	rw.mutex.Lock()
-	defer rw.mutex.Unlock()
+	// Per-command tracking.
+	commands *commandRegistry
 
-	// Add new event.
-	rw.events = append(rw.events, timestamp)
+	// Custom metrics registered by features.
+	registry *Registry
 
-	// Remove events outside the window.
-	cutoff := timestamp.Add(-rw.window)
-	validEvents := make([]time.Time, 0, len(rw.events))
+	// Totals of previous sessions, restored from durable storage.
+	previous LifetimeTotals
 
-	for _, event := range rw.events {
-		if event.After(cutoff) {
-			validEvents = append(validEvents, event)
-		}
-	}
+	// Rate, history and latency tracking.
+	history        *history
+	gatewayLatency *latencySamples
+	commandWindow  *RateWindow
+	apiWindow      *RateWindow
+	mutex          sync.RWMutex
 
-	rw.events = validEvents
-}
-
-// Rate calculates the current rate per second.
-func (rw *RateWindow) Rate() float64 {
-	rw.mutex.Lock()
-	defer rw.mutex.Unlock()
-
-	if len(rw.events) == 0 {
-		return 0.0
-	}
-
-	// Remove expired events.
-	now := time.Now()
-	cutoff := now.Add(-rw.window)
-	validEvents := 0
-
-	for _, event := range rw.events {
-		if event.After(cutoff) {
-			validEvents++
-		}
-	}
-
-	// Calculate rate per second.
-	windowSeconds := rw.window.Seconds()
-
-	return float64(validEvents) / windowSeconds
+	// Push exporter that receives individual timings, if one is running.
+	statsd atomic.Pointer[StatsDExporter]
 }
 
 var globalMetrics *Metrics
@@ -105,24 +61,33 @@ var once sync.Once
 // Initialize sets up the global metrics instance.
 func Initialize() *Metrics {
 	once.Do(func() {
-		globalMetrics = &Metrics{
-			ErrorsByType:  make(map[botErrors.ErrorType]int64),
-			BotStartTime:  time.Now(),
-			commandWindow: NewRateWindow(60 * time.Second), // 1-minute window.
-			apiWindow:     NewRateWindow(60 * time.Second), // 1-minute window.
-		}
+		globalMetrics = newMetrics()
 	})
 
 	return globalMetrics
 }
 
-// Get returns the global metrics instance.
-func Get() *Metrics {
-	if globalMetrics == nil {
-		return Initialize()
+// newMetrics creates an empty metrics instance.
+func newMetrics() *Metrics {
+	now := time.Now()
+
+	return &Metrics{
+		ErrorsByType: make(map[botErrors.ErrorType]int64),
+		BotStartTime: now,
+		commands:     newCommandRegistry(),
+		registry:     NewRegistry(),
+		history:      newHistory(),
+
+		gatewayStatusSince: now,
+		gatewayLatency:     newLatencySamples(gatewaySampleCapacity),
+		commandWindow:      NewRateWindow(RateWindowLong),
+		apiWindow:          NewRateWindow(RateWindowLong),
 	}
+}
 
-	return globalMetrics
+// Get returns the global metrics instance.
+func Get() *Metrics {
+	return Initialize()
 }
 
 // IncrementCommands increments command counters.
@@ -141,7 +106,7 @@ func (m *Metrics) IncrementCommands(successful bool) {
 	m.mutex.Unlock()
 
 	m.commandWindow.Add(now)
-	m.CommandsPerSecond = m.commandWindow.Rate()
+	m.history.recordCommand(now, successful)
 }
 
 // IncrementAPIRequests increments API request counters.
@@ -162,15 +127,74 @@ func (m *Metrics) IncrementAPIRequests(successful bool, responseTimeMs int64) {
 	m.mutex.Unlock()
 
 	m.apiWindow.Add(now)
-	m.APIRequestsPerSecond = m.apiWindow.Rate()
+	m.history.recordAPIRequest(now, responseTimeMs)
+
+	if exporter := m.statsd.Load(); exporter != nil {
+		exporter.recordTiming("api.request.duration", time.Duration(responseTimeMs)*time.Millisecond,
+			label{"result", resultLabel(successful)})
+	}
 }
 
 // IncrementError increments error counter by type.
 func (m *Metrics) IncrementError(errorType botErrors.ErrorType) {
+	m.mutex.Lock()
+	m.ErrorsByType[errorType]++
+	m.mutex.Unlock()
+
+	m.history.recordError(time.Now())
+}
+
+// SetGatewayLatency records the latest Discord gateway heartbeat latency.
+func (m *Metrics) SetGatewayLatency(latency time.Duration) {
+	m.mutex.Lock()
+	m.GatewayLatency = latency
+	m.mutex.Unlock()
+
+	m.gatewayLatency.add(latency)
+}
+
+// SetGatewayConnected records whether the Discord gateway connection is up.
+// The time of the change is kept only when the state actually changes.
+func (m *Metrics) SetGatewayConnected(connected bool) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	m.ErrorsByType[errorType]++
+	if m.gatewayConnected != connected {
+		m.gatewayConnected = connected
+		m.gatewayStatusSince = time.Now()
+	}
+}
+
+// GetGatewayStatus reports whether the Discord gateway is connected and since when.
+func (m *Metrics) GetGatewayStatus() (connected bool, since time.Time) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.gatewayConnected, m.gatewayStatusSince
+}
+
+// GetErrorCount returns the number of errors of the given type recorded this session.
+func (m *Metrics) GetErrorCount(errorType botErrors.ErrorType) int64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.ErrorsByType[errorType]
+}
+
+// GetGatewayLatency returns the latest Discord gateway heartbeat latency.
+func (m *Metrics) GetGatewayLatency() time.Duration {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.GatewayLatency
+}
+
+// apiResponseTotals returns the accumulated API response time in milliseconds and the response count.
+func (m *Metrics) apiResponseTotals() (sumMs, count int64) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.APIResponseTimeSum, m.APIResponseCount
 }
 
 // GetAverageResponseTime calculates the average API response time.
@@ -187,9 +211,17 @@ func (m *Metrics) GetAverageResponseTime() float64 {
 	return float64(responseTimeSum) / float64(responseCount)
 }
 
+// GetStartTime returns when the bot started.
+func (m *Metrics) GetStartTime() time.Time {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.BotStartTime
+}
+
 // GetUptime returns the bot uptime.
 func (m *Metrics) GetUptime() time.Duration {
-	return time.Since(m.BotStartTime)
+	return time.Since(m.GetStartTime())
 }
 
 // GetSuccessRate calculates the command success rate as a percentage.
@@ -223,26 +255,41 @@ func (m *Metrics) GetAPISuccessRate() float64 {
 // Summary returns a comprehensive metrics summary.
 type Summary struct {
 	// Command statistics.
-	CommandsTotal      int64   `json:"commands_total"`
-	CommandsSuccessful int64   `json:"commands_successful"`
-	CommandsFailed     int64   `json:"commands_failed"`
-	CommandsPerSecond  float64 `json:"commands_per_second"`
-	CommandSuccessRate float64 `json:"command_success_rate_percent"`
+	CommandsTotal      int64        `json:"commands_total"`
+	CommandsSuccessful int64        `json:"commands_successful"`
+	CommandsFailed     int64        `json:"commands_failed"`
+	CommandsPerSecond  float64      `json:"commands_per_second"`
+	CommandRates       RateAverages `json:"command_rates"`
+	CommandSuccessRate float64      `json:"command_success_rate_percent"`
 
 	// API statistics.
-	APIRequestsTotal      int64   `json:"api_requests_total"`
-	APIRequestsSuccessful int64   `json:"api_requests_successful"`
-	APIRequestsFailed     int64   `json:"api_requests_failed"`
-	APIRequestsPerSecond  float64 `json:"api_requests_per_second"`
-	APISuccessRate        float64 `json:"api_success_rate_percent"`
-	AverageResponseTime   float64 `json:"average_response_time_ms"`
+	APIRequestsTotal      int64        `json:"api_requests_total"`
+	APIRequestsSuccessful int64        `json:"api_requests_successful"`
+	APIRequestsFailed     int64        `json:"api_requests_failed"`
+	APIRequestsPerSecond  float64      `json:"api_requests_per_second"`
+	APIRequestRates       RateAverages `json:"api_request_rates"`
+	APISuccessRate        float64      `json:"api_success_rate_percent"`
+	AverageResponseTime   float64      `json:"average_response_time_ms"`
+
+	// Per-command statistics, most used first.
+	Commands []CommandStats `json:"commands"`
 
 	// Error statistics.
 	ErrorsByType map[botErrors.ErrorType]int64 `json:"errors_by_type"`
 
+	// Custom metrics registered by features.
+	Custom []FamilySnapshot `json:"custom"`
+
 	// System statistics.
 	UptimeSeconds float64 `json:"uptime_seconds"`
 	BotStartTime  string  `json:"bot_start_time"`
+
+	// Health statistics.
+	GatewayLatency LatencySummary `json:"gateway_latency"`
+	Runtime        RuntimeSummary `json:"runtime"`
+
+	// Lifetime statistics across every session, including this one.
+	Lifetime LifetimeSummary `json:"lifetime"`
 }
 
 // GetSummary returns a comprehensive metrics summary.
@@ -254,19 +301,14 @@ func (m *Metrics) GetSummary() Summary {
 		errorsByType[k] = v
 	}
 
-	m.mutex.RUnlock()
-
-	m.mutex.RLock()
 	summary := Summary{
 		CommandsTotal:         m.CommandsTotal,
 		CommandsSuccessful:    m.CommandsSuccessful,
 		CommandsFailed:        m.CommandsFailed,
-		CommandsPerSecond:     m.CommandsPerSecond,
 		APIRequestsTotal:      m.APIRequestsTotal,
 		APIRequestsSuccessful: m.APIRequestsSuccessful,
 		APIRequestsFailed:     m.APIRequestsFailed,
-		APIRequestsPerSecond:  m.APIRequestsPerSecond,
-		UptimeSeconds:         m.GetUptime().Seconds(),
+		UptimeSeconds:         time.Since(m.BotStartTime).Seconds(),
 		BotStartTime:          m.BotStartTime.Format(time.RFC3339),
 		ErrorsByType:          errorsByType,
 	}
@@ -291,6 +333,16 @@ func (m *Metrics) GetSummary() Summary {
 	summary.CommandSuccessRate = commandSuccessRate
 	summary.APISuccessRate = apiSuccessRate
 	summary.AverageResponseTime = averageResponseTime
+	summary.Commands = m.commands.all()
+
+	summary.CommandRates = m.commandWindow.Averages()
+	summary.CommandsPerSecond = summary.CommandRates.OneMinute
+	summary.APIRequestRates = m.apiWindow.Averages()
+	summary.APIRequestsPerSecond = summary.APIRequestRates.OneMinute
+	summary.Custom = m.registry.Snapshot()
+	summary.Lifetime = lifetimeSummary(m.GetLifetimeTotals())
+	summary.GatewayLatency = m.gatewayLatency.summary()
+	summary.Runtime = ReadRuntimeSummary()
 
 	return summary
 }
@@ -305,6 +357,16 @@ func RecordAPIRequest(successful bool, responseTimeMs int64) {
 	Get().IncrementAPIRequests(successful, responseTimeMs)
 }
 
+// RecordGatewayLatency is a convenience function to record gateway heartbeat latency.
+func RecordGatewayLatency(latency time.Duration) {
+	Get().SetGatewayLatency(latency)
+}
+
+// RecordGatewayConnected is a convenience function to record the gateway connection state.
+func RecordGatewayConnected(connected bool) {
+	Get().SetGatewayConnected(connected)
+}
+
 // RecordError is a convenience function to record errors.
 func RecordError(err error) {
 	var botErr *botErrors.BotError
@@ -0,0 +1,336 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	botErrors "github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/logging"
+)
+
+// lifetimeVersion is the version of the persisted lifetime totals format.
+const lifetimeVersion = 1
+
+var (
+	// ErrStateTooNew is returned when the stored totals were written by a newer version of the bot.
+	ErrStateTooNew = botErrors.NewInternalError("metrics state is newer than this version of the bot supports", nil)
+	// ErrStateQuarantined is returned when the stored totals could not be parsed and were moved
+	// aside, so new snapshots start over without destroying them.
+	ErrStateQuarantined = botErrors.NewInternalError("metrics state could not be parsed and was moved aside", nil)
+)
+
+// CommandTotals holds the lifetime counters of a single command.
+type CommandTotals struct {
+	Total  int64 `json:"total"`
+	Failed int64 `json:"failed"`
+}
+
+// LifetimeTotals holds counters accumulated across every bot session.
+type LifetimeTotals struct {
+	Version        int       `json:"version"`
+	FirstStartTime time.Time `json:"first_start_time"`
+	SavedAt        time.Time `json:"saved_at"`
+	Starts         int64     `json:"starts"`
+	UptimeSeconds  float64   `json:"uptime_seconds"`
+
+	CommandsTotal      int64 `json:"commands_total"`
+	CommandsSuccessful int64 `json:"commands_successful"`
+	CommandsFailed     int64 `json:"commands_failed"`
+
+	APIRequestsTotal      int64 `json:"api_requests_total"`
+	APIRequestsSuccessful int64 `json:"api_requests_successful"`
+	APIRequestsFailed     int64 `json:"api_requests_failed"`
+	APIResponseTimeSum    int64 `json:"api_response_time_sum_ms"`
+	APIResponseCount      int64 `json:"api_response_count"`
+
+	ErrorsByType map[botErrors.ErrorType]int64 `json:"errors_by_type"`
+	Commands     map[string]CommandTotals      `json:"commands"`
+}
+
+// LifetimeSummary reports totals across every bot session, including the current one.
+type LifetimeSummary struct {
+	Starts             int64                         `json:"starts"`
+	FirstStartTime     string                        `json:"first_start_time"`
+	UptimeSeconds      float64                       `json:"uptime_seconds"`
+	CommandsTotal      int64                         `json:"commands_total"`
+	CommandsSuccessful int64                         `json:"commands_successful"`
+	CommandsFailed     int64                         `json:"commands_failed"`
+	CommandSuccessRate float64                       `json:"command_success_rate_percent"`
+	APIRequestsTotal   int64                         `json:"api_requests_total"`
+	APISuccessRate     float64                       `json:"api_success_rate_percent"`
+	ErrorsByType       map[botErrors.ErrorType]int64 `json:"errors_by_type"`
+}
+
+// RestoreLifetime sets the totals of previous sessions that lifetime statistics build on.
+func (m *Metrics) RestoreLifetime(previous LifetimeTotals) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.previous = previous
+}
+
+// GetLifetimeTotals returns the totals of previous sessions combined with the current session.
+func (m *Metrics) GetLifetimeTotals() LifetimeTotals {
+	commands := m.commands.all()
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	previous := m.previous
+	totals := LifetimeTotals{
+		Version:               lifetimeVersion,
+		FirstStartTime:        previous.FirstStartTime,
+		SavedAt:               time.Now(),
+		Starts:                previous.Starts + 1,
+		UptimeSeconds:         previous.UptimeSeconds + time.Since(m.BotStartTime).Seconds(),
+		CommandsTotal:         previous.CommandsTotal + m.CommandsTotal,
+		CommandsSuccessful:    previous.CommandsSuccessful + m.CommandsSuccessful,
+		CommandsFailed:        previous.CommandsFailed + m.CommandsFailed,
+		APIRequestsTotal:      previous.APIRequestsTotal + m.APIRequestsTotal,
+		APIRequestsSuccessful: previous.APIRequestsSuccessful + m.APIRequestsSuccessful,
+		APIRequestsFailed:     previous.APIRequestsFailed + m.APIRequestsFailed,
+		APIResponseTimeSum:    previous.APIResponseTimeSum + m.APIResponseTimeSum,
+		APIResponseCount:      previous.APIResponseCount + m.APIResponseCount,
+		ErrorsByType:          make(map[botErrors.ErrorType]int64),
+		Commands:              make(map[string]CommandTotals),
+	}
+
+	if totals.FirstStartTime.IsZero() {
+		totals.FirstStartTime = m.BotStartTime
+	}
+
+	for errorType, count := range previous.ErrorsByType {
+		totals.ErrorsByType[errorType] += count
+	}
+
+	for errorType, count := range m.ErrorsByType {
+		totals.ErrorsByType[errorType] += count
+	}
+
+	for name, command := range previous.Commands {
+		totals.Commands[name] = command
+	}
+
+	for _, stats := range commands {
+		command := totals.Commands[stats.Name]
+		command.Total += stats.Total
+		command.Failed += stats.Failed
+		totals.Commands[stats.Name] = command
+	}
+
+	return totals
+}
+
+// lifetimeSummary condenses lifetime totals for the metrics summary.
+func lifetimeSummary(totals LifetimeTotals) LifetimeSummary {
+	summary := LifetimeSummary{
+		Starts:             totals.Starts,
+		FirstStartTime:     totals.FirstStartTime.Format(time.RFC3339),
+		UptimeSeconds:      totals.UptimeSeconds,
+		CommandsTotal:      totals.CommandsTotal,
+		CommandsSuccessful: totals.CommandsSuccessful,
+		CommandsFailed:     totals.CommandsFailed,
+		APIRequestsTotal:   totals.APIRequestsTotal,
+		ErrorsByType:       totals.ErrorsByType,
+	}
+
+	if totals.CommandsTotal > 0 {
+		summary.CommandSuccessRate = (float64(totals.CommandsSuccessful) / float64(totals.CommandsTotal)) * 100.0
+	}
+
+	if totals.APIRequestsTotal > 0 {
+		summary.APISuccessRate = (float64(totals.APIRequestsSuccessful) / float64(totals.APIRequestsTotal)) * 100.0
+	}
+
+	return summary
+}
+
+// Store is durable storage for lifetime totals.
+type Store interface {
+	// Load returns the stored totals, or nil when nothing has been stored yet. It returns an
+	// error wrapping ErrStateTooNew or ErrStateQuarantined when the totals cannot be used.
+	Load() (*LifetimeTotals, error)
+	// Save replaces the stored totals.
+	Save(totals LifetimeTotals) error
+}
+
+// FileStore stores lifetime totals as a JSON file.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore creates a store that keeps lifetime totals in the file at path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load reads the lifetime totals from the file.
+func (fs *FileStore) Load() (*LifetimeTotals, error) {
+	data, err := os.ReadFile(fs.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics state file: %w", err)
+	}
+
+	var totals LifetimeTotals
+	if err := json.Unmarshal(data, &totals); err != nil {
+		corrupt := fs.path + ".corrupt-" + time.Now().UTC().Format("20060102T150405Z")
+		if renameErr := os.Rename(fs.path, corrupt); renameErr != nil {
+			return nil, fmt.Errorf("failed to parse metrics state file: %w (moving it aside failed: %v)", err, renameErr)
+		}
+
+		return nil, fmt.Errorf("%w to %s: %v", ErrStateQuarantined, corrupt, err)
+	}
+
+	if totals.Version > lifetimeVersion {
+		return nil, fmt.Errorf("%w (file is at version %d, latest known is %d)", ErrStateTooNew,
+			totals.Version, lifetimeVersion)
+	}
+
+	return &totals, nil
+}
+
+// Save writes the lifetime totals to the file atomically, so a crash never leaves a partial file.
+func (fs *FileStore) Save(totals LifetimeTotals) error {
+	data, err := json.MarshalIndent(totals, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics state: %w", err)
+	}
+
+	dir := filepath.Dir(fs.path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create metrics state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(fs.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary metrics state file: %w", err)
+	}
+
+	defer func() {
+		_ = os.Remove(tmp.Name()) // No-op once renamed.
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write metrics state: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync metrics state: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close metrics state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), fs.path); err != nil {
+		return fmt.Errorf("failed to replace metrics state file: %w", err)
+	}
+
+	return nil
+}
+
+// Persister periodically snapshots lifetime totals to a Store.
+type Persister struct {
+	metrics  *Metrics
+	store    Store
+	interval time.Duration
+	// readOnly is set when the stored totals could not be restored and must not be overwritten.
+	readOnly bool
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewPersister creates a persister that snapshots the global metrics to store every interval.
+func NewPersister(store Store, interval time.Duration) *Persister {
+	return &Persister{
+		metrics:  Get(),
+		store:    store,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Restore loads the totals of previous sessions into the metrics. When the stored totals
+// cannot be read and were not moved aside, the persister stops saving, so they are kept
+// for a newer version of the bot or for manual recovery.
+func (p *Persister) Restore() error {
+	previous, err := p.store.Load()
+	if err != nil {
+		if !errors.Is(err, ErrStateQuarantined) {
+			p.readOnly = true
+			return fmt.Errorf("%w; lifetime metrics will not be saved", err)
+		}
+
+		return err
+	}
+
+	if previous == nil {
+		return nil
+	}
+
+	p.metrics.RestoreLifetime(*previous)
+
+	logging.WithComponent("metrics").Info("Restored lifetime metrics",
+		"starts", previous.Starts,
+		"commands_total", previous.CommandsTotal,
+		"saved_at", previous.SavedAt.Format(time.RFC3339),
+	)
+
+	return nil
+}
+
+// Start begins periodic snapshots in the background.
+func (p *Persister) Start() {
+	if p.readOnly {
+		return
+	}
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				if err := p.Save(); err != nil {
+					logging.WithComponent("metrics").Error("Failed to snapshot metrics", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Save writes a snapshot of the lifetime totals immediately. It does nothing when Restore
+// made the persister read-only.
+func (p *Persister) Save() error {
+	if p.readOnly {
+		return nil
+	}
+
+	return p.store.Save(p.metrics.GetLifetimeTotals())
+}
+
+// Stop ends periodic snapshots and writes a final snapshot.
+func (p *Persister) Stop() error {
+	close(p.stop)
+	p.wg.Wait()
+
+	return p.Save()
+}
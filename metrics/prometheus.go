@@ -0,0 +1,310 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	botErrors "github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/logging"
+)
+
+// namespace is the prefix applied to all bot metric names.
+const namespace = "discogo"
+
+// prometheusContentType is the content type of the Prometheus text exposition format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// label is a single Prometheus label name/value pair.
+type label struct {
+	name  string
+	value string
+}
+
+// expositionWriter writes metrics in the Prometheus text exposition format.
+// The first write error is retained and all subsequent writes become no-ops.
+type expositionWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// header writes the HELP and TYPE lines of a metric family.
+func (ew *expositionWriter) header(name, help, metricType string) {
+	ew.printf("# HELP %s %s\n", name, escapeHelp(help))
+	ew.printf("# TYPE %s %s\n", name, metricType)
+}
+
+// sample writes a single sample line.
+func (ew *expositionWriter) sample(name string, value float64, labels ...label) {
+	if len(labels) == 0 {
+		ew.printf("%s %s\n", name, formatFloat(value))
+		return
+	}
+
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", l.name, escapeLabelValue(l.value)))
+	}
+
+	ew.printf("%s{%s} %s\n", name, strings.Join(parts, ","), formatFloat(value))
+}
+
+// single writes a metric family that consists of exactly one unlabeled sample.
+func (ew *expositionWriter) single(name, help, metricType string, value float64) {
+	ew.header(name, help, metricType)
+	ew.sample(name, value)
+}
+
+// rates writes one sample per standard averaging window.
+func (ew *expositionWriter) rates(name string, averages RateAverages) {
+	ew.sample(name, averages.OneMinute, label{"window", "1m"})
+	ew.sample(name, averages.FiveMinutes, label{"window", "5m"})
+	ew.sample(name, averages.FifteenMinutes, label{"window", "15m"})
+}
+
+// histogram writes the bucket, sum and count samples of a histogram.
+func (ew *expositionWriter) histogram(name string, snapshot HistogramSnapshot, labels ...label) {
+	for i, count := range snapshot.CumulativeCounts {
+		upperBound := math.Inf(1)
+		if i < len(snapshot.UpperBounds) {
+			upperBound = snapshot.UpperBounds[i]
+		}
+
+		bucketLabels := append(append(make([]label, 0, len(labels)+1), labels...), label{"le", formatFloat(upperBound)})
+		ew.sample(name+"_bucket", float64(count), bucketLabels...)
+	}
+
+	ew.sample(name+"_sum", snapshot.Sum, labels...)
+	ew.sample(name+"_count", float64(snapshot.Count), labels...)
+}
+
+func (ew *expositionWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
+
+// WritePrometheus writes all metrics to w in the Prometheus text exposition format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	summary := m.GetSummary()
+	gatewayLatency := m.GetGatewayLatency()
+
+	ew := &expositionWriter{w: bufio.NewWriter(w)}
+
+	// Command metrics.
+	ew.header(namespace+"_commands_total", "Total number of commands executed, partitioned by result.", "counter")
+	ew.sample(namespace+"_commands_total", float64(summary.CommandsSuccessful), label{"result", "success"})
+	ew.sample(namespace+"_commands_total", float64(summary.CommandsFailed), label{"result", "failure"})
+	ew.header(namespace+"_commands_per_second", "Command rate per second, partitioned by averaging window.", "gauge")
+	ew.rates(namespace+"_commands_per_second", summary.CommandRates)
+
+	writeCommandMetrics(ew, summary.Commands, m.commands.allGuilds())
+
+	// API metrics.
+	ew.header(namespace+"_api_requests_total", "Total number of API requests made, partitioned by result.", "counter")
+	ew.sample(namespace+"_api_requests_total", float64(summary.APIRequestsSuccessful), label{"result", "success"})
+	ew.sample(namespace+"_api_requests_total", float64(summary.APIRequestsFailed), label{"result", "failure"})
+	ew.header(namespace+"_api_requests_per_second", "API request rate per second, partitioned by averaging window.", "gauge")
+	ew.rates(namespace+"_api_requests_per_second", summary.APIRequestRates)
+
+	responseTimeSum, responseCount := m.apiResponseTotals()
+	ew.header(namespace+"_api_request_duration_seconds", "API request duration in seconds.", "summary")
+	ew.sample(namespace+"_api_request_duration_seconds_sum", float64(responseTimeSum)/1000.0)
+	ew.sample(namespace+"_api_request_duration_seconds_count", float64(responseCount))
+
+	// Error metrics.
+	ew.header(namespace+"_errors_total", "Total number of errors, partitioned by error type.", "counter")
+
+	errorTypes := make([]string, 0, len(summary.ErrorsByType))
+	for errorType := range summary.ErrorsByType {
+		errorTypes = append(errorTypes, string(errorType))
+	}
+
+	sort.Strings(errorTypes)
+
+	for _, errorType := range errorTypes {
+		count := summary.ErrorsByType[botErrors.ErrorType(errorType)]
+		ew.sample(namespace+"_errors_total", float64(count), label{"type", errorType})
+	}
+
+	// Bot metrics.
+	ew.single(namespace+"_uptime_seconds", "Time since the bot started in seconds.", "gauge", summary.UptimeSeconds)
+	ew.single(namespace+"_start_time_seconds", "Start time of the bot since unix epoch in seconds.", "gauge",
+		float64(m.GetStartTime().UnixNano())/1e9)
+	ew.single(namespace+"_gateway_latency_seconds", "Latency of the last Discord gateway heartbeat in seconds.", "gauge",
+		gatewayLatency.Seconds())
+
+	gatewayUp := 0.0
+	if connected, _ := m.GetGatewayStatus(); connected {
+		gatewayUp = 1
+	}
+
+	ew.single(namespace+"_gateway_up", "Whether the Discord gateway connection is up (1) or down (0).", "gauge", gatewayUp)
+
+	ew.header(namespace+"_gateway_heartbeat_latency_seconds",
+		"Discord gateway heartbeat latency in seconds; quantiles cover recent heartbeats.", "summary")
+	ew.sample(namespace+"_gateway_heartbeat_latency_seconds", summary.GatewayLatency.P50.Seconds(), label{"quantile", "0.5"})
+	ew.sample(namespace+"_gateway_heartbeat_latency_seconds", summary.GatewayLatency.P95.Seconds(), label{"quantile", "0.95"})
+	ew.sample(namespace+"_gateway_heartbeat_latency_seconds", summary.GatewayLatency.P99.Seconds(), label{"quantile", "0.99"})
+	ew.sample(namespace+"_gateway_heartbeat_latency_seconds_sum", summary.GatewayLatency.Sum.Seconds())
+	ew.sample(namespace+"_gateway_heartbeat_latency_seconds_count", float64(summary.GatewayLatency.Count))
+
+	writeCustomMetrics(ew, summary.Custom)
+	writeRuntimeMetrics(ew)
+
+	if ew.err != nil {
+		return fmt.Errorf("failed to write metrics: %w", ew.err)
+	}
+
+	if err := ew.w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush metrics: %w", err)
+	}
+
+	return nil
+}
+
+// writeCommandMetrics writes per-command counters and latency histograms,
+// plus the per-guild breakdown when it is being tracked.
+func writeCommandMetrics(ew *expositionWriter, commands, guildCommands []CommandStats) {
+	ew.header(namespace+"_command_executions_total", "Total number of executions per command, partitioned by result.", "counter")
+
+	for _, stats := range commands {
+		ew.sample(namespace+"_command_executions_total", float64(stats.Successful),
+			label{"command", stats.Name}, label{"result", "success"})
+		ew.sample(namespace+"_command_executions_total", float64(stats.Failed),
+			label{"command", stats.Name}, label{"result", "failure"})
+	}
+
+	ew.header(namespace+"_command_duration_seconds", "Command execution duration in seconds.", "histogram")
+
+	for _, stats := range commands {
+		ew.histogram(namespace+"_command_duration_seconds", stats.Duration, label{"command", stats.Name})
+	}
+
+	if len(guildCommands) == 0 {
+		return
+	}
+
+	ew.header(namespace+"_guild_command_executions_total",
+		"Total number of executions per command and guild, partitioned by result.", "counter")
+
+	for _, stats := range guildCommands {
+		ew.sample(namespace+"_guild_command_executions_total", float64(stats.Successful),
+			label{"guild", stats.GuildID}, label{"command", stats.Name}, label{"result", "success"})
+		ew.sample(namespace+"_guild_command_executions_total", float64(stats.Failed),
+			label{"guild", stats.GuildID}, label{"command", stats.Name}, label{"result", "failure"})
+	}
+
+	ew.header(namespace+"_guild_command_duration_seconds", "Command execution duration per guild in seconds.", "histogram")
+
+	for _, stats := range guildCommands {
+		ew.histogram(namespace+"_guild_command_duration_seconds", stats.Duration,
+			label{"guild", stats.GuildID}, label{"command", stats.Name})
+	}
+}
+
+// writeCustomMetrics writes the metrics registered by features, prefixed with the bot namespace.
+func writeCustomMetrics(ew *expositionWriter, families []FamilySnapshot) {
+	for _, f := range families {
+		name := namespace + "_" + f.Name
+		ew.header(name, f.Help, string(f.Type))
+
+		for _, s := range f.Series {
+			labels := make([]label, 0, len(f.LabelNames))
+			for _, labelName := range f.LabelNames {
+				labels = append(labels, label{labelName, s.Labels[labelName]})
+			}
+
+			if s.Histogram != nil {
+				ew.histogram(name, *s.Histogram, labels...)
+			} else {
+				ew.sample(name, s.Value, labels...)
+			}
+		}
+	}
+}
+
+// writeRuntimeMetrics writes Go runtime statistics using the standard go_* metric names.
+func writeRuntimeMetrics(ew *expositionWriter) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	ew.header("go_info", "Information about the Go environment.", "gauge")
+	ew.sample("go_info", 1, label{"version", runtime.Version()})
+
+	ew.single("go_goroutines", "Number of goroutines that currently exist.", "gauge", float64(runtime.NumGoroutine()))
+	ew.single("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", "gauge", float64(memStats.Alloc))
+	ew.single("go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", "counter",
+		float64(memStats.TotalAlloc))
+	ew.single("go_memstats_sys_bytes", "Number of bytes obtained from system.", "gauge", float64(memStats.Sys))
+	ew.single("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", "gauge",
+		float64(memStats.HeapAlloc))
+	ew.single("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", "gauge", float64(memStats.HeapInuse))
+	ew.single("go_memstats_heap_objects", "Number of allocated objects.", "gauge", float64(memStats.HeapObjects))
+	ew.single("go_memstats_gc_cpu_fraction", "The fraction of this program's available CPU time used by the GC since the program started.",
+		"gauge", memStats.GCCPUFraction)
+
+	runtimeStats := runtimeSummary(&memStats)
+
+	ew.header("go_gc_duration_seconds", "A summary of the pause duration of garbage collection cycles.", "summary")
+	ew.sample("go_gc_duration_seconds", runtimeStats.GCPauseP50.Seconds(), label{"quantile", "0.5"})
+	ew.sample("go_gc_duration_seconds", runtimeStats.GCPauseP99.Seconds(), label{"quantile", "0.99"})
+	ew.sample("go_gc_duration_seconds", runtimeStats.GCPauseMax.Seconds(), label{"quantile", "1"})
+	ew.sample("go_gc_duration_seconds_sum", float64(memStats.PauseTotalNs)/1e9)
+	ew.sample("go_gc_duration_seconds_count", float64(memStats.NumGC))
+}
+
+// Handler returns an HTTP handler that serves the global metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		var buf bytes.Buffer
+		if err := Get().WritePrometheus(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", prometheusContentType)
+
+		if _, err := buf.WriteTo(w); err != nil {
+			logging.WithComponent("metrics").Debug("Failed to write metrics response", "error", err)
+		}
+	})
+}
+
+// formatFloat formats a sample value, including the special values Prometheus understands.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// escapeHelp escapes a HELP docstring.
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+// escapeLabelValue escapes a label value.
+func escapeLabelValue(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Standard rate windows, reported like load averages.
+const (
+	RateWindowShort  = 1 * time.Minute
+	RateWindowMedium = 5 * time.Minute
+	RateWindowLong   = 15 * time.Minute
+)
+
+// rateBucket counts the events that happened during one second.
+type rateBucket struct {
+	second int64 // unix second this bucket currently holds.
+	count  int64
+}
+
+// RateWindow tracks events in a fixed-size ring of per-second buckets.
+// Recording an event is O(1) and memory is bounded by the window length,
+// regardless of traffic.
+type RateWindow struct {
+	buckets []rateBucket
+	window  time.Duration
+	mutex   sync.Mutex
+}
+
+// RateAverages holds event rates per second over the standard windows.
+type RateAverages struct {
+	OneMinute      float64 `json:"1m"`
+	FiveMinutes    float64 `json:"5m"`
+	FifteenMinutes float64 `json:"15m"`
+}
+
+// NewRateWindow creates a new rate tracking window covering at most window.
+// Rates can be queried for any sub-window up to that length.
+func NewRateWindow(window time.Duration) *RateWindow {
+	size := int(window / time.Second)
+	if size < 1 {
+		size = 1
+	}
+
+	return &RateWindow{
+		buckets: make([]rateBucket, size),
+		window:  time.Duration(size) * time.Second,
+	}
+}
+
+// Add records an event timestamp.
+func (rw *RateWindow) Add(timestamp time.Time) {
+	rw.AddN(timestamp, 1)
+}
+
+// AddN records n events at timestamp.
+func (rw *RateWindow) AddN(timestamp time.Time, n int64) {
+	second := timestamp.Unix()
+	bucket := &rw.buckets[rw.index(second)]
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	if bucket.second != second {
+		// The bucket holds a second that has fallen out of the window; reuse it.
+		bucket.second = second
+		bucket.count = 0
+	}
+
+	bucket.count += n
+}
+
+// Rate calculates the current rate per second over the whole window.
+func (rw *RateWindow) Rate() float64 {
+	return rw.RateOver(rw.window)
+}
+
+// RateOver calculates the current rate per second over the last window,
+// capped to the length the RateWindow was created with.
+func (rw *RateWindow) RateOver(window time.Duration) float64 {
+	return rw.rateAt(time.Now(), window)
+}
+
+// Averages returns the 1, 5 and 15 minute rates, each capped to the window length.
+func (rw *RateWindow) Averages() RateAverages {
+	now := time.Now()
+
+	return RateAverages{
+		OneMinute:      rw.rateAt(now, RateWindowShort),
+		FiveMinutes:    rw.rateAt(now, RateWindowMedium),
+		FifteenMinutes: rw.rateAt(now, RateWindowLong),
+	}
+}
+
+// rateAt calculates the rate per second over the window ending at now.
+func (rw *RateWindow) rateAt(now time.Time, window time.Duration) float64 {
+	if window > rw.window {
+		window = rw.window
+	}
+
+	seconds := int64(window / time.Second)
+	if seconds < 1 {
+		return 0.0
+	}
+
+	current := now.Unix()
+	oldest := current - seconds
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	var total int64
+
+	for i := range rw.buckets {
+		if rw.buckets[i].second > oldest && rw.buckets[i].second <= current {
+			total += rw.buckets[i].count
+		}
+	}
+
+	return float64(total) / float64(seconds)
+}
+
+func (rw *RateWindow) index(second int64) int {
+	size := int64(len(rw.buckets))
+
+	return int(((second % size) + size) % size)
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// sliceRateWindow is the RateWindow that kept every event timestamp, which RateWindow
+// replaced. It is kept here to compare the two.
+type sliceRateWindow struct {
+	events []time.Time
+	window time.Duration
+	mutex  sync.Mutex
+}
+
+func newSliceRateWindow(window time.Duration) *sliceRateWindow {
+	return &sliceRateWindow{
+		events: make([]time.Time, 0),
+		window: window,
+	}
+}
+
+func (rw *sliceRateWindow) Add(timestamp time.Time) {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	rw.events = append(rw.events, timestamp)
+
+	cutoff := timestamp.Add(-rw.window)
+	validEvents := make([]time.Time, 0, len(rw.events))
+
+	for _, event := range rw.events {
+		if event.After(cutoff) {
+			validEvents = append(validEvents, event)
+		}
+	}
+
+	rw.events = validEvents
+}
+
+func TestRateWindowConcurrentAdd(t *testing.T) {
+	const (
+		goroutines = 8
+		events     = 1000
+	)
+
+	rw := NewRateWindow(RateWindowShort)
+	now := time.Now()
+
+	var wg sync.WaitGroup
+
+	for range goroutines {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for range events {
+				rw.Add(now)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for range events {
+				rw.Rate()
+				rw.Averages()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	want := float64(goroutines*events) / RateWindowShort.Seconds()
+	if got := rw.rateAt(now, RateWindowShort); got != want {
+		t.Errorf("rate after concurrent adds = %v, want %v", got, want)
+	}
+}
+
+func TestRateWindowExpiresOldEvents(t *testing.T) {
+	rw := NewRateWindow(RateWindowShort)
+	start := time.Unix(1_700_000_000, 0)
+
+	rw.AddN(start, 60)
+	rw.AddN(start.Add(RateWindowShort), 30)
+
+	if got := rw.rateAt(start.Add(RateWindowShort), RateWindowShort); got != 0.5 {
+		t.Errorf("rate = %v, want 0.5", got)
+	}
+}
+
+func BenchmarkRateWindowAdd(b *testing.B) {
+	b.Run("ring", func(b *testing.B) {
+		rw := NewRateWindow(RateWindowShort)
+		now := time.Now()
+
+		for i := range b.N {
+			rw.Add(now.Add(time.Duration(i) * time.Millisecond))
+		}
+	})
+
+	b.Run("slice", func(b *testing.B) {
+		rw := newSliceRateWindow(RateWindowShort)
+		now := time.Now()
+
+		for i := range b.N {
+			rw.Add(now.Add(time.Duration(i) * time.Millisecond))
+		}
+	})
+}
+
+func BenchmarkRateWindowAddParallel(b *testing.B) {
+	b.Run("ring", func(b *testing.B) {
+		rw := NewRateWindow(RateWindowShort)
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				rw.Add(time.Now())
+			}
+		})
+	})
+
+	b.Run("slice", func(b *testing.B) {
+		rw := newSliceRateWindow(RateWindowShort)
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				rw.Add(time.Now())
+			}
+		})
+	})
+}
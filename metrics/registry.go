@@ -0,0 +1,375 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dunamismax/discogo/logging"
+)
+
+// MetricType is the kind of a registered metric.
+type MetricType string
+
+const (
+	// MetricTypeCounter is a monotonically increasing value.
+	MetricTypeCounter MetricType = "counter"
+	// MetricTypeGauge is a value that can go up and down.
+	MetricTypeGauge MetricType = "gauge"
+	// MetricTypeHistogram is a distribution of observations in buckets.
+	MetricTypeHistogram MetricType = "histogram"
+)
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// labelSeparator joins label values into series keys; it cannot appear in valid UTF-8 text.
+const labelSeparator = "\xff"
+
+// Registry holds metrics registered by features at runtime.
+type Registry struct {
+	families map[string]*family
+	mutex    sync.RWMutex
+}
+
+// family is a named metric and all of its labeled series.
+type family struct {
+	name       string
+	help       string
+	metricType MetricType
+	labelNames []string
+	buckets    []float64
+	series     map[string]*series
+	mutex      sync.Mutex
+}
+
+// series is a single labeled value of a family.
+type series struct {
+	labelValues []string
+	value       float64
+	histogram   *Histogram
+}
+
+// NewRegistry creates an empty metrics registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// register adds a family, or returns the existing one when an identical definition
+// was already registered, so features can register from constructors safely.
+func (r *Registry) register(name, help string, metricType MetricType, buckets []float64, labelNames []string) (*family, error) {
+	if !metricNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid metric name %q", name)
+	}
+
+	for _, labelName := range labelNames {
+		if !labelNamePattern.MatchString(labelName) || strings.HasPrefix(labelName, "__") {
+			return nil, fmt.Errorf("invalid label name %q for metric %q", labelName, name)
+		}
+
+		if metricType == MetricTypeHistogram && labelName == "le" {
+			return nil, fmt.Errorf("label name %q is reserved for histogram %q", labelName, name)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, ok := r.families[name]; ok {
+		if existing.metricType != metricType || !slices.Equal(existing.labelNames, labelNames) {
+			return nil, fmt.Errorf("metric %q is already registered as a %s with labels %v",
+				name, existing.metricType, existing.labelNames)
+		}
+
+		return existing, nil
+	}
+
+	f := &family{
+		name:       name,
+		help:       help,
+		metricType: metricType,
+		labelNames: append([]string(nil), labelNames...),
+		buckets:    append([]float64(nil), buckets...),
+		series:     make(map[string]*series),
+	}
+	r.families[name] = f
+
+	return f, nil
+}
+
+// NewCounter registers a counter with the given label names.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) (*Counter, error) {
+	f, err := r.register(name, help, MetricTypeCounter, nil, labelNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Counter{family: f}, nil
+}
+
+// NewGauge registers a gauge with the given label names.
+func (r *Registry) NewGauge(name, help string, labelNames ...string) (*Gauge, error) {
+	f, err := r.register(name, help, MetricTypeGauge, nil, labelNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Gauge{family: f}, nil
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds and label names.
+// DefaultDurationBuckets is used when buckets is empty.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) (*HistogramVec, error) {
+	if len(buckets) == 0 {
+		buckets = DefaultDurationBuckets
+	}
+
+	f, err := r.register(name, help, MetricTypeHistogram, buckets, labelNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistogramVec{family: f}, nil
+}
+
+// seriesFor returns the series for the given label values, creating it on first use.
+// The caller must hold the family mutex.
+func (f *family) seriesFor(labelValues []string) (*series, bool) {
+	if len(labelValues) != len(f.labelNames) {
+		logging.WithComponent("metrics").Warn("Dropping metric update with wrong number of label values",
+			"metric", f.name,
+			"expected", len(f.labelNames),
+			"got", len(labelValues),
+		)
+
+		return nil, false
+	}
+
+	key := strings.Join(labelValues, labelSeparator)
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.metricType == MetricTypeHistogram {
+			s.histogram = NewHistogram(f.buckets)
+		}
+
+		f.series[key] = s
+	}
+
+	return s, true
+}
+
+// Counter is a registered counter.
+type Counter struct {
+	family *family
+}
+
+// Inc increments the counter series identified by labelValues by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter series identified by labelValues by delta.
+// Negative deltas are ignored because counters never decrease.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+
+	c.family.mutex.Lock()
+	defer c.family.mutex.Unlock()
+
+	if s, ok := c.family.seriesFor(labelValues); ok {
+		s.value += delta
+	}
+}
+
+// Gauge is a registered gauge.
+type Gauge struct {
+	family *family
+}
+
+// Set sets the gauge series identified by labelValues.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.family.mutex.Lock()
+	defer g.family.mutex.Unlock()
+
+	if s, ok := g.family.seriesFor(labelValues); ok {
+		s.value = value
+	}
+}
+
+// Add adds delta, which may be negative, to the gauge series identified by labelValues.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.family.mutex.Lock()
+	defer g.family.mutex.Unlock()
+
+	if s, ok := g.family.seriesFor(labelValues); ok {
+		s.value += delta
+	}
+}
+
+// Inc increments the gauge series identified by labelValues by one.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec decrements the gauge series identified by labelValues by one.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// HistogramVec is a registered histogram.
+type HistogramVec struct {
+	family *family
+}
+
+// Observe records an observation in the histogram series identified by labelValues.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.family.mutex.Lock()
+	s, ok := h.family.seriesFor(labelValues)
+	h.family.mutex.Unlock()
+
+	if ok {
+		s.histogram.Observe(value)
+	}
+}
+
+// SeriesSnapshot is a point-in-time copy of one labeled series.
+type SeriesSnapshot struct {
+	Labels    map[string]string  `json:"labels,omitempty"`
+	Value     float64            `json:"value"`
+	Histogram *HistogramSnapshot `json:"histogram,omitempty"`
+
+	labelValues []string
+}
+
+// FamilySnapshot is a point-in-time copy of a registered metric and its series.
+type FamilySnapshot struct {
+	Name       string           `json:"name"`
+	Help       string           `json:"help"`
+	Type       MetricType       `json:"type"`
+	LabelNames []string         `json:"label_names,omitempty"`
+	Series     []SeriesSnapshot `json:"series"`
+}
+
+// Total returns the sum of all series values, or the total observation count for histograms.
+func (fs FamilySnapshot) Total() float64 {
+	total := 0.0
+
+	for _, s := range fs.Series {
+		if s.Histogram != nil {
+			total += float64(s.Histogram.Count)
+		} else {
+			total += s.Value
+		}
+	}
+
+	return total
+}
+
+// Snapshot returns a copy of every registered metric, sorted by name.
+func (r *Registry) Snapshot() []FamilySnapshot {
+	r.mutex.RLock()
+
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+
+	r.mutex.RUnlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	snapshots := make([]FamilySnapshot, 0, len(families))
+	for _, f := range families {
+		snapshots = append(snapshots, f.snapshot())
+	}
+
+	return snapshots
+}
+
+func (f *family) snapshot() FamilySnapshot {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	snapshot := FamilySnapshot{
+		Name:       f.name,
+		Help:       f.help,
+		Type:       f.metricType,
+		LabelNames: append([]string(nil), f.labelNames...),
+		Series:     make([]SeriesSnapshot, 0, len(f.series)),
+	}
+
+	for _, s := range f.series {
+		seriesSnapshot := SeriesSnapshot{
+			Value:       s.value,
+			labelValues: s.labelValues,
+		}
+
+		if len(f.labelNames) > 0 {
+			seriesSnapshot.Labels = make(map[string]string, len(f.labelNames))
+			for i, labelName := range f.labelNames {
+				seriesSnapshot.Labels[labelName] = s.labelValues[i]
+			}
+		}
+
+		if s.histogram != nil {
+			histogram := s.histogram.Snapshot()
+			seriesSnapshot.Histogram = &histogram
+		}
+
+		snapshot.Series = append(snapshot.Series, seriesSnapshot)
+	}
+
+	sort.Slice(snapshot.Series, func(i, j int) bool {
+		return strings.Join(snapshot.Series[i].labelValues, labelSeparator) <
+			strings.Join(snapshot.Series[j].labelValues, labelSeparator)
+	})
+
+	return snapshot
+}
+
+// Registry returns the registry for custom metrics.
+func (m *Metrics) Registry() *Registry {
+	return m.registry
+}
+
+// RegisterCounter registers a counter in the global registry.
+// It panics on an invalid or conflicting definition, which is a programming error.
+func RegisterCounter(name, help string, labelNames ...string) *Counter {
+	counter, err := Get().Registry().NewCounter(name, help, labelNames...)
+	if err != nil {
+		panic(err)
+	}
+
+	return counter
+}
+
+// RegisterGauge registers a gauge in the global registry.
+// It panics on an invalid or conflicting definition, which is a programming error.
+func RegisterGauge(name, help string, labelNames ...string) *Gauge {
+	gauge, err := Get().Registry().NewGauge(name, help, labelNames...)
+	if err != nil {
+		panic(err)
+	}
+
+	return gauge
+}
+
+// RegisterHistogram registers a histogram in the global registry.
+// It panics on an invalid or conflicting definition, which is a programming error.
+func RegisterHistogram(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	histogram, err := Get().Registry().NewHistogram(name, help, buckets, labelNames...)
+	if err != nil {
+		panic(err)
+	}
+
+	return histogram
+}
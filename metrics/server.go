@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dunamismax/discogo/logging"
+)
+
+// MetricsPath is the HTTP path the Prometheus exposition endpoint is served on.
+const MetricsPath = "/metrics"
+
+// Server exposes metrics over HTTP for scraping.
+type Server struct {
+	httpServer *http.Server
+}
+
+// NewServer creates a metrics HTTP server listening on addr.
+func NewServer(addr string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, Handler())
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// Start binds the listen address and serves metrics in the background.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+
+	logger := logging.WithComponent("metrics")
+	logger.Info("Metrics endpoint listening", "address", listener.Addr().String(), "path", MetricsPath)
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server stopped unexpectedly", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown gracefully stops the metrics server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down metrics server: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,353 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dunamismax/discogo/logging"
+)
+
+// Default StatsD exporter settings.
+const (
+	defaultStatsDPrefix   = namespace
+	defaultStatsDInterval = 10 * time.Second
+
+	// statsDMaxPacketSize keeps datagrams below a typical Ethernet MTU so they are not fragmented.
+	statsDMaxPacketSize = 1432
+	// statsDMaxPendingTimings bounds the timings buffered between flushes.
+	statsDMaxPendingTimings = 10000
+)
+
+// StatsDConfig configures a StatsDExporter.
+type StatsDConfig struct {
+	// Addr is the host:port of the StatsD server.
+	Addr string
+	// Prefix is prepended to every metric name, separated by a dot.
+	Prefix string
+	// Interval is how often metrics are flushed.
+	Interval time.Duration
+	// Tags are constant key:value tags added to every metric.
+	Tags []string
+	// DogStatsD sends tags in the DogStatsD format. Plain StatsD has no tags,
+	// so otherwise the tag values are appended to the metric name.
+	DogStatsD bool
+}
+
+// StatsDExporter pushes metrics to a StatsD server over UDP.
+//
+// Counters are sent as the increase since the previous flush, gauges as their current
+// value, and command and API request durations as individual timings.
+type StatsDExporter struct {
+	config   StatsDConfig
+	metrics  *Metrics
+	conn     net.Conn
+	previous map[string]float64 // counter values at the previous flush, keyed by metric line.
+	timings  []string
+	dropped  int64
+	mutex    sync.Mutex // guards timings and dropped.
+	flushMu  sync.Mutex // serializes flushes.
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewStatsDExporter creates an exporter for the global metrics. Zero settings fall back to defaults.
+func NewStatsDExporter(config StatsDConfig) (*StatsDExporter, error) {
+	if config.Addr == "" {
+		return nil, fmt.Errorf("statsd address is required")
+	}
+
+	if config.Prefix == "" {
+		config.Prefix = defaultStatsDPrefix
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = defaultStatsDInterval
+	}
+
+	// Constant tags are already in key:value form, so only the separators are replaced.
+	tags := make([]string, 0, len(config.Tags))
+	for _, tag := range config.Tags {
+		tags = append(tags, strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_").Replace(tag))
+	}
+
+	config.Tags = tags
+
+	conn, err := net.Dial("udp", config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd at %s: %w", config.Addr, err)
+	}
+
+	return &StatsDExporter{
+		config:   config,
+		metrics:  Get(),
+		conn:     conn,
+		previous: make(map[string]float64),
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+// Start starts recording timings and flushing metrics in the background.
+func (e *StatsDExporter) Start() {
+	e.metrics.statsd.Store(e)
+
+	logger := logging.WithComponent("metrics")
+	logger.Info("StatsD exporter started", "address", e.config.Addr, "interval", e.config.Interval)
+
+	e.wg.Add(1)
+
+	go e.run()
+}
+
+// Stop stops the exporter, flushes the remaining metrics and closes the connection.
+func (e *StatsDExporter) Stop() error {
+	e.stopOnce.Do(func() { close(e.stop) })
+	e.wg.Wait()
+	e.metrics.statsd.CompareAndSwap(e, nil)
+
+	flushErr := e.Flush()
+
+	if err := e.conn.Close(); err != nil {
+		return fmt.Errorf("failed to close statsd connection: %w", err)
+	}
+
+	return flushErr
+}
+
+func (e *StatsDExporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			if err := e.Flush(); err != nil {
+				logging.WithComponent("metrics").Warn("Failed to flush StatsD metrics", "error", err)
+			}
+		}
+	}
+}
+
+// Flush sends the current metrics and the timings recorded since the previous flush.
+func (e *StatsDExporter) Flush() error {
+	e.flushMu.Lock()
+	defer e.flushMu.Unlock()
+
+	lines := e.collect()
+
+	e.mutex.Lock()
+	lines = append(lines, e.timings...)
+	e.timings = nil
+	dropped := e.dropped
+	e.dropped = 0
+	e.mutex.Unlock()
+
+	if dropped > 0 {
+		logging.WithComponent("metrics").Warn("Dropped StatsD timings because the buffer was full", "dropped", dropped)
+	}
+
+	return e.send(lines)
+}
+
+// collect builds the counter and gauge lines for the current metrics.
+func (e *StatsDExporter) collect() []string {
+	summary := e.metrics.GetSummary()
+
+	var lines []string
+
+	counter := func(name string, value float64, tags ...label) {
+		line := e.line(name, "", "c", tags)
+		delta := value - e.previous[line]
+
+		// A counter that went backwards was reset, so everything it holds is new.
+		if delta < 0 {
+			delta = value
+		}
+
+		e.previous[line] = value
+
+		if delta > 0 {
+			lines = append(lines, e.line(name, formatStatsDValue(delta), "c", tags))
+		}
+	}
+
+	gauge := func(name string, value float64, tags ...label) {
+		lines = append(lines, e.line(name, formatStatsDValue(value), "g", tags))
+	}
+
+	// Command metrics.
+	counter("commands", float64(summary.CommandsSuccessful), label{"result", "success"})
+	counter("commands", float64(summary.CommandsFailed), label{"result", "failure"})
+
+	for _, stats := range summary.Commands {
+		counter("command.executions", float64(stats.Successful), label{"command", stats.Name}, label{"result", "success"})
+		counter("command.executions", float64(stats.Failed), label{"command", stats.Name}, label{"result", "failure"})
+	}
+
+	for _, stats := range e.metrics.commands.allGuilds() {
+		counter("guild.command.executions", float64(stats.Successful),
+			label{"guild", stats.GuildID}, label{"command", stats.Name}, label{"result", "success"})
+		counter("guild.command.executions", float64(stats.Failed),
+			label{"guild", stats.GuildID}, label{"command", stats.Name}, label{"result", "failure"})
+	}
+
+	// API metrics.
+	counter("api.requests", float64(summary.APIRequestsSuccessful), label{"result", "success"})
+	counter("api.requests", float64(summary.APIRequestsFailed), label{"result", "failure"})
+
+	// Error metrics.
+	for errorType, count := range summary.ErrorsByType {
+		counter("errors", float64(count), label{"error_type", string(errorType)})
+	}
+
+	// Bot metrics.
+	gatewayUp := 0.0
+	if connected, _ := e.metrics.GetGatewayStatus(); connected {
+		gatewayUp = 1
+	}
+
+	gauge("uptime_seconds", summary.UptimeSeconds)
+	gauge("gateway.up", gatewayUp)
+	gauge("gateway.latency_ms", float64(e.metrics.GetGatewayLatency().Milliseconds()))
+	gauge("runtime.goroutines", float64(runtime.NumGoroutine()))
+	gauge("runtime.heap_alloc_bytes", float64(summary.Runtime.HeapAllocBytes))
+
+	// Custom metrics.
+	for _, f := range summary.Custom {
+		for _, s := range f.Series {
+			tags := make([]label, 0, len(f.LabelNames))
+			for _, labelName := range f.LabelNames {
+				tags = append(tags, label{labelName, s.Labels[labelName]})
+			}
+
+			switch {
+			case s.Histogram != nil:
+				counter(f.Name+".count", float64(s.Histogram.Count), tags...)
+				counter(f.Name+".sum", s.Histogram.Sum, tags...)
+			case f.Type == MetricTypeCounter:
+				counter(f.Name, s.Value, tags...)
+			default:
+				gauge(f.Name, s.Value, tags...)
+			}
+		}
+	}
+
+	return lines
+}
+
+// recordTiming buffers a timing until the next flush.
+func (e *StatsDExporter) recordTiming(name string, duration time.Duration, tags ...label) {
+	line := e.line(name, formatStatsDValue(float64(duration.Microseconds())/1000), "ms", tags)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.timings) >= statsDMaxPendingTimings {
+		e.dropped++
+		return
+	}
+
+	e.timings = append(e.timings, line)
+}
+
+// line formats a single StatsD line. An empty value leaves the value out, which is
+// used to key counters independently of their value.
+func (e *StatsDExporter) line(name, value, metricType string, tags []label) string {
+	var b strings.Builder
+
+	b.WriteString(e.config.Prefix)
+	b.WriteByte('.')
+	b.WriteString(sanitizeStatsD(name))
+
+	if !e.config.DogStatsD {
+		for _, tag := range tags {
+			b.WriteByte('.')
+			b.WriteString(sanitizeStatsD(tag.value))
+		}
+	}
+
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(metricType)
+
+	if e.config.DogStatsD && len(tags)+len(e.config.Tags) > 0 {
+		b.WriteString("|#")
+
+		b.WriteString(strings.Join(e.config.Tags, ","))
+
+		for i, tag := range tags {
+			if i > 0 || len(e.config.Tags) > 0 {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(sanitizeStatsD(tag.name))
+			b.WriteByte(':')
+			b.WriteString(sanitizeStatsD(tag.value))
+		}
+	}
+
+	return b.String()
+}
+
+// send writes lines to the server, packing as many as fit into each datagram.
+func (e *StatsDExporter) send(lines []string) error {
+	var packet bytes.Buffer
+
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+
+		_, err := e.conn.Write(packet.Bytes())
+		packet.Reset()
+
+		if err != nil {
+			return fmt.Errorf("failed to send statsd packet: %w", err)
+		}
+
+		return nil
+	}
+
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsDMaxPacketSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+
+		packet.WriteString(line)
+	}
+
+	return flush()
+}
+
+// sanitizeStatsD replaces characters that have a meaning in the StatsD line format.
+func sanitizeStatsD(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', ',', '#', '\n', ' ':
+			return '_'
+		default:
+			return r
+		}
+	}, s)
+}
+
+func formatStatsDValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenStatsD binds a UDP socket standing in for a StatsD server.
+func listenStatsD(t *testing.T) net.PacketConn {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+// readStatsD returns the lines received until no datagram arrived for a short while.
+func readStatsD(t *testing.T, conn net.PacketConn) []string {
+	t.Helper()
+
+	var lines []string
+
+	buf := make([]byte, 65536)
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return lines
+		}
+
+		if n > statsDMaxPacketSize {
+			t.Errorf("datagram of %d bytes exceeds %d", n, statsDMaxPacketSize)
+		}
+
+		lines = append(lines, strings.Split(string(buf[:n]), "\n")...)
+	}
+}
+
+// flushStatsD records a command execution through a started exporter and returns the
+// lines of one flush.
+func flushStatsD(t *testing.T, config StatsDConfig) []string {
+	t.Helper()
+
+	conn := listenStatsD(t)
+	config.Addr = conn.LocalAddr().String()
+	config.Interval = time.Hour
+
+	exporter, err := NewStatsDExporter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh instance keeps the lines independent of what other tests recorded globally.
+	m := newMetrics()
+	exporter.metrics = m
+
+	exporter.Start()
+
+	m.IncrementCommand("statsd_test", "", true, 5*time.Millisecond)
+
+	if err := exporter.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	return readStatsD(t, conn)
+}
+
+func assertLines(t *testing.T, lines []string, want ...string) {
+	t.Helper()
+
+	received := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		received[line] = true
+	}
+
+	for _, line := range want {
+		if !received[line] {
+			t.Errorf("line %q not received; got:\n%s", line, strings.Join(lines, "\n"))
+		}
+	}
+}
+
+func TestStatsDExporterDogStatsD(t *testing.T) {
+	lines := flushStatsD(t, StatsDConfig{Prefix: "test", Tags: []string{"env:test"}, DogStatsD: true})
+
+	assertLines(t, lines,
+		"test.command.executions:1|c|#env:test,command:statsd_test,result:success",
+		"test.command.duration:5|ms|#env:test,command:statsd_test,result:success",
+		"test.gateway.up:0|g|#env:test",
+	)
+}
+
+func TestStatsDExporterPlain(t *testing.T) {
+	lines := flushStatsD(t, StatsDConfig{Prefix: "test", Tags: []string{"env:test"}})
+
+	assertLines(t, lines,
+		"test.command.executions.statsd_test.success:1|c",
+		"test.command.duration.statsd_test.success:5|ms",
+		"test.gateway.up:0|g",
+	)
+
+	for _, line := range lines {
+		if strings.Contains(line, "|#") {
+			t.Errorf("plain StatsD line %q has DogStatsD tags", line)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Transport is an http.RoundTripper that records API request metrics for every request.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base, or http.DefaultTransport when base is nil, with API request metrics.
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{Base: base}
+}
+
+// RoundTrip executes a single HTTP transaction and records its outcome and duration.
+// Responses with a status code of 400 or above count as failures.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	duration := time.Since(start)
+
+	successful := err == nil && resp.StatusCode < http.StatusBadRequest
+	RecordAPIRequest(successful, duration.Milliseconds())
+
+	if err != nil {
+		return nil, fmt.Errorf("api request failed: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/logging"
+	"github.com/dunamismax/discogo/storage"
+	"github.com/dunamismax/discogo/storage/migrations"
+)
+
+// migrateUsage describes the migrate command.
+const migrateUsage = `usage: discord-bot [flags] migrate <command>
+
+commands:
+  status             list the migrations and whether they have been applied
+  up [version]       apply the pending migrations, up to version if given
+  down <version>     roll back the migrations newer than version`
+
+// runMigrate runs the migrate command, which lists and applies storage migrations without
+// connecting to Discord, and returns the exit code.
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	store, err := storage.Open(storage.Config{Backend: cfg.StorageBackend, Path: cfg.StoragePath})
+	if err != nil {
+		logging.Error("Failed to open storage", "error", err)
+		return 1
+	}
+
+	defer func() {
+		if err := store.Close(); err != nil {
+			logging.Error("Error closing storage", "error", err)
+		}
+	}()
+
+	migrator, err := storage.NewMigrator(store, migrations.All, cfg.StorageBackupDir)
+	if err != nil {
+		logging.Error("Invalid migrations", "error", err)
+		return 1
+	}
+
+	target := migrator.Latest()
+
+	switch {
+	case args[0] == "status" && len(args) == 1:
+		return printMigrationStatus(migrator)
+	case args[0] == "up" && len(args) <= 2:
+		if len(args) == 2 {
+			if target, err = strconv.Atoi(args[1]); err != nil {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+
+		if current, err := migrator.Version(); err == nil && target < current {
+			logging.Error("Target version is older than the current schema; use migrate down",
+				"current", current, "target", target)
+
+			return 1
+		}
+	case args[0] == "down" && len(args) == 2:
+		if target, err = strconv.Atoi(args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+
+		if current, err := migrator.Version(); err == nil && target > current {
+			logging.Error("Target version is newer than the current schema; use migrate up",
+				"current", current, "target", target)
+
+			return 1
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if err := migrator.Migrate(target); err != nil {
+		logging.Error("Migration failed", "error", err)
+		return 1
+	}
+
+	return printMigrationStatus(migrator)
+}
+
+// printMigrationStatus prints every migration and the current schema version.
+func printMigrationStatus(migrator *storage.Migrator) int {
+	statuses, version, err := migrator.Status()
+	if err != nil {
+		logging.Error("Failed to read migration status", "error", err)
+		return 1
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tSTATUS\tDESCRIPTION")
+
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied {
+			state = "applied"
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\n", status.Version, state, status.Description)
+	}
+
+	_ = writer.Flush()
+
+	fmt.Printf("\nSchema version %d, latest known %d.\n", version, migrator.Latest())
+
+	if version > migrator.Latest() {
+		fmt.Println("The storage was migrated by a newer version of the bot; this version will refuse to start.")
+	}
+
+	return 0
+}
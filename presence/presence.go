@@ -0,0 +1,106 @@
+// Package presence parses and renders the statuses the bot rotates through in its presence.
+//
+// A status is written as "kind: text", such as "watching: {{.Guilds}} servers". The text is
+// a text/template filled from Data. Without a kind, the status is a custom status.
+package presence
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/metrics"
+)
+
+// maxLength is the longest activity text Discord shows.
+const maxLength = 128
+
+// Kind is the kind of activity a status shows, which Discord displays before its text.
+type Kind string
+
+// Status kinds.
+const (
+	Playing   Kind = "playing"
+	Watching  Kind = "watching"
+	Listening Kind = "listening"
+	Competing Kind = "competing"
+	Custom    Kind = "custom"
+)
+
+// Kinds lists every status kind.
+var Kinds = []Kind{Playing, Watching, Listening, Competing, Custom}
+
+// Data fills the templates of statuses.
+type Data struct {
+	// BotName is the configured name of the bot.
+	BotName string
+	// Prefix is the default command prefix.
+	Prefix string
+	// Guilds is the number of guilds the bot is in.
+	Guilds int
+	// Commands is the number of commands served across every session.
+	Commands int64
+	// Uptime is how long the bot has been running, formatted for display.
+	Uptime string
+	// Summary holds every metric of the bot.
+	Summary metrics.Summary
+}
+
+// Status is a parsed status.
+type Status struct {
+	Kind     Kind
+	template *template.Template
+}
+
+// Parse parses a status written as "kind: text". It checks the template against Data, so
+// a status that parses also renders. Errors describe the problem for configuration reports.
+func Parse(spec string) (Status, error) {
+	status := Status{Kind: Custom}
+	text := strings.TrimSpace(spec)
+
+	if name, rest, ok := strings.Cut(text, ":"); ok {
+		for _, kind := range Kinds {
+			if strings.EqualFold(strings.TrimSpace(name), string(kind)) {
+				status.Kind = kind
+				text = strings.TrimSpace(rest)
+
+				break
+			}
+		}
+	}
+
+	if text == "" {
+		return Status{}, fmt.Errorf("status %q has no text", spec)
+	}
+
+	tmpl, err := template.New(string(status.Kind)).Parse(text)
+	if err != nil {
+		return Status{}, fmt.Errorf("invalid status template %q: %w", spec, err)
+	}
+
+	if err := tmpl.Execute(io.Discard, Data{}); err != nil {
+		return Status{}, fmt.Errorf("invalid status template %q: %w", spec, err)
+	}
+
+	status.template = tmpl
+
+	return status, nil
+}
+
+// Render fills the status template, shortening the text to what Discord shows.
+func (s Status) Render(data Data) (string, error) {
+	var text bytes.Buffer
+	if err := s.template.Execute(&text, data); err != nil {
+		return "", errors.NewInternalError("failed to render status", err)
+	}
+
+	rendered := []rune(strings.TrimSpace(text.String()))
+	if len(rendered) > maxLength {
+		rendered = append(rendered[:maxLength-1], '…')
+	}
+
+	return string(rendered), nil
+}
@@ -0,0 +1,350 @@
+// Package privacy keeps the privacy choices of users, and finds and deletes the records the
+// bot stores about a user.
+//
+// Features that store records about a user must key them with UserKey, in any namespace,
+// so the records are shown to the user and deleted on request:
+//
+//	err := levels.Put(tx, guildID, privacy.UserKey(userID, ""), level)
+package privacy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/storage"
+)
+
+// Feature is the storage feature name of the users' privacy choices.
+const Feature = "privacy"
+
+// AuditFeature is the storage feature name of the audit log of privacy requests.
+const AuditFeature = "privacy_audit"
+
+// Category is a kind of tracking users can opt out of.
+type Category string
+
+// Tracking categories. Features that add tracking add a category for it here.
+const (
+	// Analytics counts the commands users run in the usage statistics.
+	Analytics Category = "analytics"
+	// MessageLogs identifies users by ID and name in the logs and traces of their commands.
+	MessageLogs Category = "message_logs"
+)
+
+// Categories lists every tracking category.
+var Categories = []Category{Analytics, MessageLogs}
+
+// ParseCategory returns the category with the given name, or a validation error.
+func ParseCategory(name string) (Category, error) {
+	for _, category := range Categories {
+		if strings.EqualFold(string(category), name) {
+			return category, nil
+		}
+	}
+
+	names := make([]string, 0, len(Categories))
+	for _, category := range Categories {
+		names = append(names, string(category))
+	}
+
+	return "", errors.NewValidationError(fmt.Sprintf("Unknown category %q. Use %s or all.", name, strings.Join(names, ", ")))
+}
+
+// UserKey returns the key of a record about a user: the user ID, followed by id if given.
+func UserKey(userID, id string) string {
+	if id == "" {
+		return userID
+	}
+
+	return userID + "/" + id
+}
+
+// Record is a stored record about a user.
+type Record struct {
+	Namespace storage.Namespace
+	Key       string
+	Value     []byte
+}
+
+// Audit actions.
+const (
+	ActionView   = "view"
+	ActionOptOut = "opt_out"
+	ActionOptIn  = "opt_in"
+	ActionDelete = "delete"
+)
+
+// AuditEntry records a privacy request. Entries are kept when the user's data is deleted,
+// as the record that the request was carried out.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	UserID  string    `json:"user_id"`
+	Action  string    `json:"action"`
+	Details string    `json:"details,omitempty"`
+}
+
+// choices are the privacy choices of a user.
+type choices struct {
+	OptOuts   []Category `json:"opt_outs"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+var (
+	choiceDocuments = storage.NewCollection[choices](Feature)
+	auditEntries    = storage.NewCollection[AuditEntry](AuditFeature)
+)
+
+// Store reads and writes privacy choices, and finds and deletes user records. It caches
+// the choices of every user it has read, since they are checked for every command. It is
+// safe for concurrent use.
+type Store struct {
+	store storage.Store
+
+	mutex sync.RWMutex
+	cache map[string]map[Category]bool
+	// generation counts writes, so a load that raced with a write is not cached.
+	generation uint64
+}
+
+// NewStore creates a privacy store.
+func NewStore(store storage.Store) *Store {
+	return &Store{
+		store: store,
+		cache: make(map[string]map[Category]bool),
+	}
+}
+
+// OptedOut reports whether a user opted out of a tracking category. When the choices cannot
+// be read, the user is treated as opted out.
+func (s *Store) OptedOut(userID string, category Category) bool {
+	optOuts, err := s.optOuts(userID)
+	if err != nil {
+		return true
+	}
+
+	return optOuts[category]
+}
+
+// OptOuts returns the categories a user opted out of, in the order of Categories.
+func (s *Store) OptOuts(userID string) ([]Category, error) {
+	optOuts, err := s.optOuts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []Category
+
+	for _, category := range Categories {
+		if optOuts[category] {
+			categories = append(categories, category)
+		}
+	}
+
+	return categories, nil
+}
+
+// SetOptOut opts a user out of the categories, or back in when optOut is false, and audits
+// the change. A user who opts back in to everything has no choices stored.
+func (s *Store) SetOptOut(userID string, categories []Category, optOut bool) error {
+	action := ActionOptIn
+	if optOut {
+		action = ActionOptOut
+	}
+
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, string(category))
+	}
+
+	err := s.store.Update(func(tx storage.Tx) error {
+		current, err := choiceDocuments.Get(tx, "", UserKey(userID, ""))
+		if err != nil && err != storage.ErrNotFound {
+			return err
+		}
+
+		optOuts := make(map[Category]bool)
+		for _, category := range current.OptOuts {
+			optOuts[category] = true
+		}
+
+		for _, category := range categories {
+			optOuts[category] = optOut
+		}
+
+		updated := choices{UpdatedAt: time.Now().UTC()}
+
+		for _, category := range Categories {
+			if optOuts[category] {
+				updated.OptOuts = append(updated.OptOuts, category)
+			}
+		}
+
+		if len(updated.OptOuts) == 0 {
+			err = choiceDocuments.Delete(tx, "", UserKey(userID, ""))
+		} else {
+			err = choiceDocuments.Put(tx, "", UserKey(userID, ""), updated)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return audit(tx, userID, action, strings.Join(names, ","))
+	})
+	if err != nil {
+		return errors.NewInternalError("failed to save privacy choices", err)
+	}
+
+	s.invalidate(userID)
+
+	return nil
+}
+
+// Records returns every record stored about a user, except the audit log, and audits the
+// request.
+func (s *Store) Records(userID string) ([]Record, error) {
+	var records []Record
+
+	err := s.store.Update(func(tx storage.Tx) error {
+		err := scan(tx, userID, func(ns storage.Namespace, key string, value []byte) error {
+			records = append(records, Record{Namespace: ns, Key: key, Value: value})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit(tx, userID, ActionView, fmt.Sprintf("%d records", len(records)))
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to read user records", err)
+	}
+
+	return records, nil
+}
+
+// Delete deletes every record stored about a user, in every namespace, and returns how many
+// were deleted. The user's privacy choices are kept, so the opt-outs stay in effect, and so
+// is the audit log.
+func (s *Store) Delete(userID string) (int, error) {
+	var deleted int
+
+	err := s.store.Update(func(tx storage.Tx) error {
+		var records []Record
+
+		err := scan(tx, userID, func(ns storage.Namespace, key string, _ []byte) error {
+			if ns.Feature() != Feature {
+				records = append(records, Record{Namespace: ns, Key: key})
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if err := tx.Bucket(record.Namespace).Delete(record.Key); err != nil {
+				return err
+			}
+		}
+
+		deleted = len(records)
+
+		return audit(tx, userID, ActionDelete, fmt.Sprintf("%d records", deleted))
+	})
+	if err != nil {
+		return 0, errors.NewInternalError("failed to delete user records", err)
+	}
+
+	return deleted, nil
+}
+
+// optOuts returns the categories a user opted out of, reading them from storage on first use.
+func (s *Store) optOuts(userID string) (map[Category]bool, error) {
+	s.mutex.RLock()
+	optOuts, ok := s.cache[userID]
+	generation := s.generation
+	s.mutex.RUnlock()
+
+	if ok {
+		return optOuts, nil
+	}
+
+	var current choices
+
+	err := s.store.View(func(tx storage.Tx) error {
+		var err error
+
+		current, err = choiceDocuments.Get(tx, "", UserKey(userID, ""))
+		if err == storage.ErrNotFound {
+			return nil
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to load privacy choices", err)
+	}
+
+	optOuts = make(map[Category]bool, len(current.OptOuts))
+	for _, category := range current.OptOuts {
+		optOuts[category] = true
+	}
+
+	s.mutex.Lock()
+	if s.generation == generation {
+		s.cache[userID] = optOuts
+	}
+	s.mutex.Unlock()
+
+	return optOuts, nil
+}
+
+// invalidate drops the cached choices of a user.
+func (s *Store) invalidate(userID string) {
+	s.mutex.Lock()
+	delete(s.cache, userID)
+	s.generation++
+	s.mutex.Unlock()
+}
+
+// scan calls fn for every record about a user, in namespace and key order, skipping the
+// audit log.
+func scan(tx storage.Tx, userID string, fn func(ns storage.Namespace, key string, value []byte) error) error {
+	namespaces, err := tx.Namespaces()
+	if err != nil {
+		return err
+	}
+
+	for _, ns := range namespaces {
+		if ns.Feature() == AuditFeature {
+			continue
+		}
+
+		err := tx.Bucket(ns).Scan(userID, func(key string, value []byte) error {
+			if key != userID && !strings.HasPrefix(key, userID+"/") {
+				return nil
+			}
+
+			return fn(ns, key, value)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// audit appends an entry to the audit log. Keys start with the time, so entries are listed
+// in order and are not taken for records about the user.
+func audit(tx storage.Tx, userID, action, details string) error {
+	entry := AuditEntry{Time: time.Now().UTC(), UserID: userID, Action: action, Details: details}
+	key := entry.Time.Format("20060102T150405.000000000Z") + "-" + userID
+
+	return auditEntries.Put(tx, "", key, entry)
+}
@@ -0,0 +1,297 @@
+// Package rules provides per-guild and per-channel rules that allow or deny commands, or
+// whole categories of commands.
+//
+// A rule applies to the whole guild, or to one channel. The rule that decides whether a
+// command can be used in a channel is, in order:
+//
+//  1. a rule for the command in the channel;
+//  2. a rule for the command's category in the channel;
+//  3. a rule allowing the command, or its category, in other channels only: the command
+//     is then denied everywhere else;
+//  4. a rule for the command in the guild;
+//  5. a rule for the command's category in the guild.
+//
+// Commands without rules are allowed.
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/storage"
+)
+
+// Feature is the storage feature name of the rules. Each guild's rules are kept in its own
+// namespace, keyed by scope and target.
+const Feature = "command_rules"
+
+// categoryPrefix starts the target of a rule for a category of commands.
+const categoryPrefix = "category:"
+
+// guildScope is the scope of the rules applying to the whole guild.
+const guildScope = "guild"
+
+// Effect tells whether a rule allows or denies its target.
+type Effect string
+
+// Rule effects.
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+)
+
+// Rule allows or denies a command, or a category of commands.
+type Rule struct {
+	// ChannelID is the channel the rule applies to; empty for the whole guild.
+	ChannelID string
+	// Target is a command name, or CategoryTarget of a category.
+	Target string
+	// Effect allows or denies the target.
+	Effect Effect
+}
+
+// CategoryTarget returns the target of a rule for a category of commands.
+func CategoryTarget(category string) string {
+	return categoryPrefix + category
+}
+
+// Category returns the category a rule applies to, if it applies to a category.
+func (r Rule) Category() (string, bool) {
+	return strings.CutPrefix(r.Target, categoryPrefix)
+}
+
+// key returns the storage key of the rule.
+func (r Rule) key() string {
+	scope := guildScope
+	if r.ChannelID != "" {
+		scope = r.ChannelID
+	}
+
+	return scope + "/" + r.Target
+}
+
+// Decision is the outcome of checking a command against the rules.
+type Decision struct {
+	// Allowed reports whether the command can be used.
+	Allowed bool
+	// Rule is the rule that decided; nil when no rule applies.
+	Rule *Rule
+	// Channels lists the channels the command is restricted to, when it was denied
+	// because it is only allowed there.
+	Channels []string
+}
+
+// Validate checks a stored rule, such as one from an imported archive, and returns the
+// value to store.
+func Validate(key string, value []byte) ([]byte, error) {
+	rule, err := parse(key, string(value))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(rule.Effect), nil
+}
+
+// Store reads and writes the rules of guilds. It caches the rules of every guild it has
+// read, so checking a command does not touch the database. It is safe for concurrent use.
+type Store struct {
+	store storage.Store
+
+	mutex sync.RWMutex
+	cache map[string][]Rule
+	// generation counts invalidations, so a load that raced with a write is not cached.
+	generation uint64
+}
+
+// NewStore creates a rules store.
+func NewStore(store storage.Store) *Store {
+	return &Store{
+		store: store,
+		cache: make(map[string][]Rule),
+	}
+}
+
+// List returns the rules of a guild, guild-wide rules first, then by channel and target.
+func (s *Store) List(guildID string) ([]Rule, error) {
+	return s.rules(guildID)
+}
+
+// Set stores a rule, replacing any rule for the same target in the same scope.
+func (s *Store) Set(guildID string, rule Rule) error {
+	return s.write(guildID, func(bucket storage.Bucket) error {
+		return bucket.Put(rule.key(), []byte(rule.Effect))
+	})
+}
+
+// Remove deletes the rule for a target in a channel, or in the whole guild when channelID
+// is empty. It reports whether there was such a rule.
+func (s *Store) Remove(guildID, channelID, target string) (bool, error) {
+	key := Rule{ChannelID: channelID, Target: target}.key()
+	removed := false
+
+	err := s.write(guildID, func(bucket storage.Bucket) error {
+		if _, err := bucket.Get(key); err != nil {
+			if err == storage.ErrNotFound {
+				return nil
+			}
+
+			return err
+		}
+
+		removed = true
+
+		return bucket.Delete(key)
+	})
+
+	return removed, err
+}
+
+// Check decides whether a command of the given category can be used in a channel.
+func (s *Store) Check(guildID, channelID, command, category string) (Decision, error) {
+	rules, err := s.rules(guildID)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	return decide(rules, channelID, command, CategoryTarget(category)), nil
+}
+
+// Invalidate drops the cached rules of a guild. Call it after changing the guild's rules in
+// storage without the Store, for example when importing a backup.
+func (s *Store) Invalidate(guildID string) {
+	s.mutex.Lock()
+	delete(s.cache, guildID)
+	s.generation++
+	s.mutex.Unlock()
+}
+
+// rules returns the rules of a guild, reading them from storage on first use.
+func (s *Store) rules(guildID string) ([]Rule, error) {
+	s.mutex.RLock()
+	rules, ok := s.cache[guildID]
+	generation := s.generation
+	s.mutex.RUnlock()
+
+	if ok {
+		return rules, nil
+	}
+
+	rules = nil
+
+	err := s.store.View(func(tx storage.Tx) error {
+		return tx.Bucket(storage.GuildNamespace(Feature, guildID)).Scan("", func(key string, value []byte) error {
+			rule, err := parse(key, string(value))
+			if err != nil {
+				return fmt.Errorf("invalid rule %q: %w", key, err)
+			}
+
+			rules = append(rules, rule)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to load command rules", err)
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].ChannelID == "" && rules[j].ChannelID != ""
+	})
+
+	s.mutex.Lock()
+	if s.generation == generation {
+		s.cache[guildID] = rules
+	}
+	s.mutex.Unlock()
+
+	return rules, nil
+}
+
+// write changes a guild's rules in storage and drops its cached copy.
+func (s *Store) write(guildID string, fn func(bucket storage.Bucket) error) error {
+	err := s.store.Update(func(tx storage.Tx) error {
+		return fn(tx.Bucket(storage.GuildNamespace(Feature, guildID)))
+	})
+	if err != nil {
+		return errors.NewInternalError("failed to save command rules", err)
+	}
+
+	s.Invalidate(guildID)
+
+	return nil
+}
+
+// decide applies the rules to a command and its category target in a channel.
+func decide(rules []Rule, channelID, command, category string) Decision {
+	find := func(channelID, target string) *Rule {
+		for i := range rules {
+			if rules[i].ChannelID == channelID && rules[i].Target == target {
+				return &rules[i]
+			}
+		}
+
+		return nil
+	}
+
+	for _, target := range []string{command, category} {
+		if rule := find(channelID, target); rule != nil {
+			return Decision{Allowed: rule.Effect == Allow, Rule: rule}
+		}
+	}
+
+	var (
+		restriction *Rule
+		channels    []string
+	)
+
+	for i, rule := range rules {
+		if rule.ChannelID != "" && rule.Effect == Allow && (rule.Target == command || rule.Target == category) {
+			if restriction == nil {
+				restriction = &rules[i]
+			}
+
+			channels = append(channels, rule.ChannelID)
+		}
+	}
+
+	if restriction != nil {
+		return Decision{Allowed: false, Rule: restriction, Channels: channels}
+	}
+
+	for _, target := range []string{command, category} {
+		if rule := find("", target); rule != nil {
+			return Decision{Allowed: rule.Effect == Allow, Rule: rule}
+		}
+	}
+
+	return Decision{Allowed: true}
+}
+
+// parse decodes a stored rule.
+func parse(key, value string) (Rule, error) {
+	scope, target, ok := strings.Cut(key, "/")
+	if !ok || target == "" || target == categoryPrefix {
+		return Rule{}, i18n.ValidationError("rules.invalid_key", key)
+	}
+
+	rule := Rule{Target: target, Effect: Effect(value)}
+
+	if scope != guildScope {
+		if _, err := strconv.ParseUint(scope, 10, 64); err != nil {
+			return Rule{}, i18n.ValidationError("rules.invalid_channel", scope, key)
+		}
+
+		rule.ChannelID = scope
+	}
+
+	if rule.Effect != Allow && rule.Effect != Deny {
+		return Rule{}, i18n.ValidationError("rules.invalid_effect", value)
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,167 @@
+package rules
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dunamismax/discogo/storage"
+)
+
+const (
+	guildID  = "111111111111111111"
+	channel  = "222222222222222222"
+	channel2 = "333333333333333333"
+)
+
+func TestCheckPrecedence(t *testing.T) {
+	general := CategoryTarget("general")
+
+	tests := []struct {
+		name         string
+		rules        []Rule
+		channelID    string
+		wantAllowed  bool
+		wantRule     *Rule
+		wantChannels []string
+	}{
+		{
+			name:        "no rules",
+			channelID:   channel,
+			wantAllowed: true,
+		},
+		{
+			name:        "guild command rule",
+			rules:       []Rule{{Target: "ping", Effect: Deny}},
+			channelID:   channel,
+			wantAllowed: false,
+			wantRule:    &Rule{Target: "ping", Effect: Deny},
+		},
+		{
+			name:        "guild command rule over guild category rule",
+			rules:       []Rule{{Target: general, Effect: Deny}, {Target: "ping", Effect: Allow}},
+			channelID:   channel,
+			wantAllowed: true,
+			wantRule:    &Rule{Target: "ping", Effect: Allow},
+		},
+		{
+			name:        "guild category rule",
+			rules:       []Rule{{Target: general, Effect: Deny}},
+			channelID:   channel,
+			wantAllowed: false,
+			wantRule:    &Rule{Target: general, Effect: Deny},
+		},
+		{
+			name:        "channel category rule over guild command rule",
+			rules:       []Rule{{Target: "ping", Effect: Deny}, {ChannelID: channel, Target: general, Effect: Allow}},
+			channelID:   channel,
+			wantAllowed: true,
+			wantRule:    &Rule{ChannelID: channel, Target: general, Effect: Allow},
+		},
+		{
+			name:        "channel command rule over channel category rule",
+			rules:       []Rule{{ChannelID: channel, Target: general, Effect: Allow}, {ChannelID: channel, Target: "ping", Effect: Deny}},
+			channelID:   channel,
+			wantAllowed: false,
+			wantRule:    &Rule{ChannelID: channel, Target: "ping", Effect: Deny},
+		},
+		{
+			name:        "channel rule of another channel does not apply",
+			rules:       []Rule{{ChannelID: channel2, Target: "ping", Effect: Deny}},
+			channelID:   channel,
+			wantAllowed: true,
+		},
+		{
+			name:         "allowed in other channels only",
+			rules:        []Rule{{ChannelID: channel2, Target: "ping", Effect: Allow}},
+			channelID:    channel,
+			wantAllowed:  false,
+			wantRule:     &Rule{ChannelID: channel2, Target: "ping", Effect: Allow},
+			wantChannels: []string{channel2},
+		},
+		{
+			name:         "restriction over guild allow",
+			rules:        []Rule{{Target: "ping", Effect: Allow}, {ChannelID: channel2, Target: general, Effect: Allow}},
+			channelID:    channel,
+			wantAllowed:  false,
+			wantRule:     &Rule{ChannelID: channel2, Target: general, Effect: Allow},
+			wantChannels: []string{channel2},
+		},
+		{
+			name:        "allowed in the channel it is restricted to",
+			rules:       []Rule{{ChannelID: channel, Target: "ping", Effect: Allow}, {ChannelID: channel2, Target: "ping", Effect: Allow}},
+			channelID:   channel,
+			wantAllowed: true,
+			wantRule:    &Rule{ChannelID: channel, Target: "ping", Effect: Allow},
+		},
+		{
+			name:        "rules of other commands do not apply",
+			rules:       []Rule{{Target: "stats", Effect: Deny}, {ChannelID: channel2, Target: "stats", Effect: Allow}},
+			channelID:   channel,
+			wantAllowed: true,
+		},
+		{
+			name:        "direct messages only follow guild rules",
+			rules:       []Rule{{ChannelID: channel2, Target: "ping", Effect: Deny}, {Target: "ping", Effect: Allow}},
+			channelID:   "",
+			wantAllowed: true,
+			wantRule:    &Rule{Target: "ping", Effect: Allow},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(storage.NewMemory())
+
+			for _, rule := range tt.rules {
+				if err := store.Set(guildID, rule); err != nil {
+					t.Fatalf("Set(%+v) failed: %v", rule, err)
+				}
+			}
+
+			decision, err := store.Check(guildID, tt.channelID, "ping", "general")
+			if err != nil {
+				t.Fatalf("Check() failed: %v", err)
+			}
+
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("Allowed = %t, want %t", decision.Allowed, tt.wantAllowed)
+			}
+
+			switch {
+			case tt.wantRule == nil && decision.Rule != nil:
+				t.Errorf("Rule = %+v, want none", *decision.Rule)
+			case tt.wantRule != nil && (decision.Rule == nil || *decision.Rule != *tt.wantRule):
+				t.Errorf("Rule = %v, want %+v", decision.Rule, *tt.wantRule)
+			}
+
+			if !slices.Equal(decision.Channels, tt.wantChannels) {
+				t.Errorf("Channels = %v, want %v", decision.Channels, tt.wantChannels)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{key: "guild/ping", value: "deny"},
+		{key: channel + "/category:general", value: "allow"},
+		{key: "guild/ping", value: "maybe", wantErr: true},
+		{key: "ping", value: "deny", wantErr: true},
+		{key: "guild/", value: "deny", wantErr: true},
+		{key: "guild/category:", value: "deny", wantErr: true},
+		{key: "general/ping", value: "deny", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			_, err := Validate(tt.key, []byte(tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q, %q) error = %v, want error: %t", tt.key, tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+// Package settings provides per-guild settings. Each guild can override the settings of
+// the schema; every other setting inherits its value from the global configuration.
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/i18n"
+)
+
+// Guild holds the effective settings of a guild.
+type Guild struct {
+	// Prefix starts commands in the guild.
+	Prefix string
+	// Locale is the language of the bot's replies, as a Discord locale such as en-US.
+	Locale string
+	// LogChannelID receives a notice of administrative actions such as settings changes.
+	LogChannelID string
+	// DisabledCommands cannot be used in the guild.
+	DisabledCommands []string
+	// SilentBlockedCommands ignores disabled or restricted commands instead of replying.
+	SilentBlockedCommands bool
+	// Moderation holds the moderation options.
+	Moderation Moderation
+}
+
+// Moderation holds the moderation options of a guild.
+type Moderation struct {
+	// FilterInvites deletes messages with invites to other servers.
+	FilterInvites bool
+	// MaxMentions is the number of user mentions a message may contain; 0 means no limit.
+	MaxMentions int
+	// ModRoleID is the role allowed to use moderation commands, besides the administrators.
+	// Its members are exempt from the moderation filters.
+	ModRoleID string
+}
+
+// CommandEnabled reports whether the command can be used in the guild.
+func (g *Guild) CommandEnabled(command string) bool {
+	for _, disabled := range g.DisabledCommands {
+		if disabled == command {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Definition describes a setting of the schema.
+type Definition struct {
+	// Key names the setting in !settings.
+	Key string
+	// Description explains the setting.
+	Description string
+	// Default returns the value used by guilds that have not set the setting.
+	Default func(cfg *config.Config) string
+
+	// normalize validates a value and returns its canonical form.
+	normalize func(value string) (string, error)
+	// apply stores a canonical value in a Guild.
+	apply func(g *Guild, value string)
+}
+
+// Normalize validates value and returns the canonical form that is stored. Invalid values
+// are reported as validation errors that can be shown to the user as they are.
+func (d Definition) Normalize(value string) (string, error) {
+	return d.normalize(strings.TrimSpace(value))
+}
+
+// MaxPrefixLength is the maximum length of a guild's command prefix.
+const MaxPrefixLength = 5
+
+// maxMentionsLimit is the highest accepted moderation.max_mentions.
+const maxMentionsLimit = config.MaxMentionsLimit
+
+// protectedCommands cannot be disabled, so administrators cannot lock themselves out.
+var protectedCommands = []string{"help", "rules", "settings"}
+
+// Protected reports whether a command cannot be disabled or restricted.
+func Protected(command string) bool {
+	for _, protected := range protectedCommands {
+		if command == protected {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Schema lists every per-guild setting.
+var Schema = []Definition{
+	{
+		Key:         "prefix",
+		Description: fmt.Sprintf("Command prefix, up to %d characters without spaces", MaxPrefixLength),
+		Default:     func(cfg *config.Config) string { return cfg.CommandPrefix },
+		normalize: func(value string) (string, error) {
+			if value == "" || len([]rune(value)) > MaxPrefixLength || strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+				return "", i18n.ValidationError("settings.invalid.prefix", MaxPrefixLength)
+			}
+
+			return value, nil
+		},
+		apply: func(g *Guild, value string) { g.Prefix = value },
+	},
+	{
+		Key:         "locale",
+		Description: "Language of the bot's replies, as a Discord locale such as en-US or de; untranslated languages use English",
+		Default:     func(*config.Config) string { return string(discordgo.EnglishUS) },
+		normalize: func(value string) (string, error) {
+			for locale := range discordgo.Locales {
+				if strings.EqualFold(string(locale), value) {
+					return string(locale), nil
+				}
+			}
+
+			return "", i18n.ValidationError("settings.invalid.locale", value)
+		},
+		apply: func(g *Guild, value string) { g.Locale = value },
+	},
+	{
+		Key:         "log_channel",
+		Description: "Channel that receives a notice of administrative actions",
+		Default:     func(*config.Config) string { return "" },
+		normalize:   idNormalizer("<#", "settings.invalid.log_channel"),
+		apply:       func(g *Guild, value string) { g.LogChannelID = value },
+	},
+	{
+		Key:         "disabled_commands",
+		Description: "Commands that cannot be used in this server, separated by commas or spaces",
+		Default:     func(*config.Config) string { return "" },
+		normalize:   normalizeCommands,
+		apply:       func(g *Guild, value string) { g.DisabledCommands = splitList(value) },
+	},
+	{
+		Key:         "silent_blocked_commands",
+		Description: "Ignore disabled or restricted commands instead of replying (on or off)",
+		Default:     func(*config.Config) string { return "off" },
+		normalize:   normalizeSwitch,
+		apply:       func(g *Guild, value string) { g.SilentBlockedCommands = value == "on" },
+	},
+	{
+		Key:         "moderation.filter_invites",
+		Description: "Delete messages with invites to other servers (on or off)",
+		Default:     func(cfg *config.Config) string { return switchValue(cfg.ModerationFilterInvites) },
+		normalize:   normalizeSwitch,
+		apply:       func(g *Guild, value string) { g.Moderation.FilterInvites = value == "on" },
+	},
+	{
+		Key:         "moderation.max_mentions",
+		Description: fmt.Sprintf("Most user mentions allowed in one message, up to %d; 0 for no limit", maxMentionsLimit),
+		Default:     func(cfg *config.Config) string { return strconv.Itoa(cfg.ModerationMaxMentions) },
+		normalize: func(value string) (string, error) {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 || limit > maxMentionsLimit {
+				return "", i18n.ValidationError("settings.invalid.max_mentions", maxMentionsLimit)
+			}
+
+			return strconv.Itoa(limit), nil
+		},
+		apply: func(g *Guild, value string) { g.Moderation.MaxMentions, _ = strconv.Atoi(value) },
+	},
+	{
+		Key:         "moderation.mod_role",
+		Description: "Role allowed to use moderation commands such as !block, besides the administrators; its members are exempt from the moderation filters",
+		Default:     func(*config.Config) string { return "" },
+		normalize:   idNormalizer("<@&", "settings.invalid.mod_role"),
+		apply:       func(g *Guild, value string) { g.Moderation.ModRoleID = value },
+	},
+}
+
+// Lookup returns the definition of the setting with the given key.
+func Lookup(key string) (Definition, bool) {
+	for _, definition := range Schema {
+		if strings.EqualFold(definition.Key, key) {
+			return definition, true
+		}
+	}
+
+	return Definition{}, false
+}
+
+// Normalize validates the value of the setting with the given key and returns its
+// canonical form, for values that do not come through Store.Set, such as imported ones.
+func Normalize(key, value string) (string, error) {
+	definition, err := lookup(key)
+	if err != nil {
+		return "", err
+	}
+
+	return definition.Normalize(value)
+}
+
+// idNormalizer returns a normalizer accepting a Discord ID or a mention starting with
+// mentionPrefix, and storing the ID. message is the catalog key of the error for other values.
+func idNormalizer(mentionPrefix, message string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		id := value
+		if strings.HasPrefix(id, mentionPrefix) && strings.HasSuffix(id, ">") {
+			id = id[len(mentionPrefix) : len(id)-1]
+		}
+
+		if !config.IsSnowflake(id) {
+			return "", i18n.ValidationError(message)
+		}
+
+		return id, nil
+	}
+}
+
+// normalizeSwitch validates an on or off value.
+func normalizeSwitch(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "on", "true", "yes", "enable", "enabled":
+		return "on", nil
+	case "off", "false", "no", "disable", "disabled":
+		return "off", nil
+	default:
+		return "", i18n.ValidationError("settings.invalid.switch")
+	}
+}
+
+// switchValue formats a boolean as the on or off value of a setting.
+func switchValue(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+
+	return "off"
+}
+
+// normalizeCommands validates a list of command names and returns it sorted, without
+// duplicates, as a comma-separated list.
+func normalizeCommands(value string) (string, error) {
+	seen := make(map[string]bool)
+
+	for _, name := range strings.FieldsFunc(strings.ToLower(value), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if Protected(name) {
+			return "", i18n.ValidationError("settings.invalid.protected", name)
+		}
+
+		seen[name] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ","), nil
+}
+
+// splitList splits a comma-separated list, skipping empty items.
+func splitList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
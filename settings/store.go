@@ -0,0 +1,232 @@
+package settings
+
+import (
+	"sync"
+
+	"github.com/dunamismax/discogo/config"
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/i18n"
+	"github.com/dunamismax/discogo/storage"
+)
+
+// Feature is the storage feature name of the settings. Each guild's overrides are kept in
+// its own namespace, one key per setting.
+const Feature = "settings"
+
+// Entry is the effective value of a setting in a guild.
+type Entry struct {
+	Definition
+	// Value is the guild's value, or the default when the guild has not set it.
+	Value string
+	// Inherited reports whether the value is the default.
+	Inherited bool
+}
+
+// Store reads and writes per-guild settings. It caches the overrides of every guild it
+// has read, so resolving the settings of a message does not touch the database. It is
+// safe for concurrent use.
+type Store struct {
+	store    storage.Store
+	defaults func() *config.Config
+
+	mutex sync.RWMutex
+	cache map[string]map[string]string
+	// generation counts invalidations, so a load that raced with a write is not cached.
+	generation uint64
+}
+
+// NewStore creates a settings store. Settings a guild has not set take their value from
+// the configuration returned by defaults, so they follow configuration reloads.
+func NewStore(store storage.Store, defaults func() *config.Config) *Store {
+	return &Store{
+		store:    store,
+		defaults: defaults,
+		cache:    make(map[string]map[string]string),
+	}
+}
+
+// Guild returns the effective settings of a guild.
+func (s *Store) Guild(guildID string) (*Guild, error) {
+	overrides, err := s.overrides(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolve(s.defaults(), overrides), nil
+}
+
+// Defaults returns the settings of a guild that has set nothing, such as those used in
+// direct messages.
+func (s *Store) Defaults() *Guild {
+	return resolve(s.defaults(), nil)
+}
+
+// List returns the effective value of every setting in a guild, in schema order.
+func (s *Store) List(guildID string) ([]Entry, error) {
+	overrides, err := s.overrides(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := s.defaults()
+	entries := make([]Entry, 0, len(Schema))
+
+	for _, definition := range Schema {
+		entries = append(entries, entry(cfg, definition, overrides))
+	}
+
+	return entries, nil
+}
+
+// Get returns the effective value of one setting in a guild.
+func (s *Store) Get(guildID, key string) (Entry, error) {
+	definition, err := lookup(key)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	overrides, err := s.overrides(guildID)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return entry(s.defaults(), definition, overrides), nil
+}
+
+// Set validates value and stores it as the guild's value of the setting.
+func (s *Store) Set(guildID, key, value string) (Entry, error) {
+	definition, err := lookup(key)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	normalized, err := definition.Normalize(value)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	err = s.write(guildID, func(bucket storage.Bucket) error {
+		return bucket.Put(definition.Key, []byte(normalized))
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{Definition: definition, Value: normalized}, nil
+}
+
+// Reset removes the guild's value of the setting, so it inherits the default again.
+func (s *Store) Reset(guildID, key string) (Entry, error) {
+	definition, err := lookup(key)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	err = s.write(guildID, func(bucket storage.Bucket) error {
+		return bucket.Delete(definition.Key)
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return entry(s.defaults(), definition, nil), nil
+}
+
+// ResetAll removes every value the guild has set.
+func (s *Store) ResetAll(guildID string) error {
+	err := s.store.Update(func(tx storage.Tx) error {
+		return tx.DeleteNamespace(storage.GuildNamespace(Feature, guildID))
+	})
+	if err != nil {
+		return errors.NewInternalError("failed to reset settings", err)
+	}
+
+	s.Invalidate(guildID)
+
+	return nil
+}
+
+// Invalidate drops the cached settings of a guild. Call it after changing the guild's
+// settings in storage without the Store, for example when importing a backup.
+func (s *Store) Invalidate(guildID string) {
+	s.mutex.Lock()
+	delete(s.cache, guildID)
+	s.generation++
+	s.mutex.Unlock()
+}
+
+// overrides returns the values a guild has set, reading them from storage on first use.
+func (s *Store) overrides(guildID string) (map[string]string, error) {
+	s.mutex.RLock()
+	overrides, ok := s.cache[guildID]
+	generation := s.generation
+	s.mutex.RUnlock()
+
+	if ok {
+		return overrides, nil
+	}
+
+	overrides = make(map[string]string)
+
+	err := s.store.View(func(tx storage.Tx) error {
+		return tx.Bucket(storage.GuildNamespace(Feature, guildID)).Scan("", func(key string, value []byte) error {
+			overrides[key] = string(value)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to load settings", err)
+	}
+
+	s.mutex.Lock()
+	if s.generation == generation {
+		s.cache[guildID] = overrides
+	}
+	s.mutex.Unlock()
+
+	return overrides, nil
+}
+
+// write changes a guild's settings in storage and drops its cached copy.
+func (s *Store) write(guildID string, fn func(bucket storage.Bucket) error) error {
+	err := s.store.Update(func(tx storage.Tx) error {
+		return fn(tx.Bucket(storage.GuildNamespace(Feature, guildID)))
+	})
+	if err != nil {
+		return errors.NewInternalError("failed to save settings", err)
+	}
+
+	s.Invalidate(guildID)
+
+	return nil
+}
+
+// lookup returns the definition of key, or a validation error.
+func lookup(key string) (Definition, error) {
+	definition, ok := Lookup(key)
+	if !ok {
+		return Definition{}, i18n.ValidationError("settings.unknown", key)
+	}
+
+	return definition, nil
+}
+
+// entry returns the effective value of a setting.
+func entry(cfg *config.Config, definition Definition, overrides map[string]string) Entry {
+	if value, ok := overrides[definition.Key]; ok {
+		return Entry{Definition: definition, Value: value}
+	}
+
+	return Entry{Definition: definition, Value: definition.Default(cfg), Inherited: true}
+}
+
+// resolve builds the effective settings from the defaults and a guild's overrides.
+func resolve(cfg *config.Config, overrides map[string]string) *Guild {
+	guild := &Guild{}
+
+	for _, definition := range Schema {
+		definition.apply(guild, entry(cfg, definition, overrides).Value)
+	}
+
+	return guild
+}
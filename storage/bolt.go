@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"bytes"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dunamismax/discogo/errors"
+	bolt "go.etcd.io/bbolt"
+	boltErrors "go.etcd.io/bbolt/errors"
+)
+
+// boltLockTimeout bounds the wait for the database file lock, which another running
+// instance of the bot may hold.
+const boltLockTimeout = 5 * time.Second
+
+// boltStore stores every namespace as a top-level bucket of a bbolt database.
+type boltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens the bbolt database at path, creating it and its directory if needed.
+func OpenBolt(path string) (Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, errors.NewInternalError("failed to create storage directory", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltLockTimeout})
+	if err != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("failed to open database %s", path), err)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+// View runs fn in a read-only transaction.
+func (s *boltStore) View(fn func(tx Tx) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	})
+}
+
+// Update runs fn in a read-write transaction.
+func (s *boltStore) Update(fn func(tx Tx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	})
+}
+
+// Backup writes a consistent copy of the database file. Writers are not blocked meanwhile.
+func (s *boltStore) Backup(w io.Writer) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// Close closes the database.
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) Bucket(ns Namespace) Bucket {
+	return boltBucket{tx: t.tx, name: []byte(ns)}
+}
+
+func (t boltTx) Namespaces() ([]Namespace, error) {
+	var namespaces []Namespace
+
+	err := t.tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		namespaces = append(namespaces, Namespace(name))
+		return nil
+	})
+
+	return namespaces, err // bbolt iterates in byte order, which is already sorted.
+}
+
+func (t boltTx) DeleteNamespace(ns Namespace) error {
+	if !t.tx.Writable() {
+		return ErrReadOnly
+	}
+
+	if err := t.tx.DeleteBucket([]byte(ns)); err != nil && !stdErrors.Is(err, boltErrors.ErrBucketNotFound) {
+		return errors.NewInternalError(fmt.Sprintf("failed to delete namespace %s", ns), err)
+	}
+
+	return nil
+}
+
+// boltBucket resolves its bbolt bucket on every call, since the bucket is only created
+// by the first write.
+type boltBucket struct {
+	tx   *bolt.Tx
+	name []byte
+}
+
+func (b boltBucket) Get(key string) ([]byte, error) {
+	bucket := b.tx.Bucket(b.name)
+	if bucket == nil {
+		return nil, ErrNotFound
+	}
+
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return nil, ErrNotFound
+	}
+
+	// Values returned by bbolt are only valid during the transaction.
+	return bytes.Clone(value), nil
+}
+
+func (b boltBucket) Put(key string, value []byte) error {
+	if !b.tx.Writable() {
+		return ErrReadOnly
+	}
+
+	bucket, err := b.tx.CreateBucketIfNotExists(b.name)
+	if err != nil {
+		return errors.NewInternalError(fmt.Sprintf("failed to create namespace %s", b.name), err)
+	}
+
+	// bbolt treats nil values as missing keys.
+	if value == nil {
+		value = []byte{}
+	}
+
+	return bucket.Put([]byte(key), value)
+}
+
+func (b boltBucket) Delete(key string) error {
+	if !b.tx.Writable() {
+		return ErrReadOnly
+	}
+
+	bucket := b.tx.Bucket(b.name)
+	if bucket == nil {
+		return nil
+	}
+
+	return bucket.Delete([]byte(key))
+}
+
+func (b boltBucket) Scan(prefix string, fn func(key string, value []byte) error) error {
+	bucket := b.tx.Bucket(b.name)
+	if bucket == nil {
+		return nil
+	}
+
+	cursor := bucket.Cursor()
+	start := []byte(prefix)
+
+	for key, value := cursor.Seek(start); key != nil && bytes.HasPrefix(key, start); key, value = cursor.Next() {
+		if err := fn(string(key), bytes.Clone(value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dunamismax/discogo/errors"
+)
+
+// Collection stores documents of type T as JSON, keyed by ID. It holds no state, so it
+// can be declared once per feature and used with any transaction:
+//
+//	var warnings = storage.NewCollection[Warning]("warnings")
+//
+//	err := store.Update(func(tx storage.Tx) error {
+//		return warnings.Put(tx, guildID, warning.ID, warning)
+//	})
+type Collection[T any] struct {
+	feature string
+}
+
+// NewCollection creates a collection for a feature. Documents are kept in the feature's
+// namespace for each guild, or its global namespace when the guild ID is empty.
+func NewCollection[T any](feature string) Collection[T] {
+	return Collection[T]{feature: feature}
+}
+
+// Namespace returns the namespace holding the documents of a guild.
+func (c Collection[T]) Namespace(guildID string) Namespace {
+	if guildID == "" {
+		return FeatureNamespace(c.feature)
+	}
+
+	return GuildNamespace(c.feature, guildID)
+}
+
+// Get returns the document with the given ID, or ErrNotFound.
+func (c Collection[T]) Get(tx Tx, guildID, id string) (T, error) {
+	var document T
+
+	data, err := tx.Bucket(c.Namespace(guildID)).Get(id)
+	if err != nil {
+		return document, err
+	}
+
+	if err := json.Unmarshal(data, &document); err != nil {
+		return document, errors.NewInternalError(fmt.Sprintf("failed to decode %s document %s", c.feature, id), err)
+	}
+
+	return document, nil
+}
+
+// Put stores a document under the given ID, replacing any previous one.
+func (c Collection[T]) Put(tx Tx, guildID, id string, document T) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return errors.NewInternalError(fmt.Sprintf("failed to encode %s document %s", c.feature, id), err)
+	}
+
+	return tx.Bucket(c.Namespace(guildID)).Put(id, data)
+}
+
+// Delete removes the document with the given ID.
+func (c Collection[T]) Delete(tx Tx, guildID, id string) error {
+	return tx.Bucket(c.Namespace(guildID)).Delete(id)
+}
+
+// ForEach calls fn for every document of a guild whose ID starts with prefix, in ID order.
+func (c Collection[T]) ForEach(tx Tx, guildID, prefix string, fn func(id string, document T) error) error {
+	return tx.Bucket(c.Namespace(guildID)).Scan(prefix, func(id string, data []byte) error {
+		var document T
+		if err := json.Unmarshal(data, &document); err != nil {
+			return errors.NewInternalError(fmt.Sprintf("failed to decode %s document %s", c.feature, id), err)
+		}
+
+		return fn(id, document)
+	})
+}
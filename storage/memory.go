@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memoryStore keeps every namespace in memory. Update works on a copy of the namespaces
+// it writes to and swaps them in on commit, so failed transactions leave no trace and
+// readers never see partial writes.
+type memoryStore struct {
+	mutex      sync.RWMutex
+	namespaces map[Namespace]map[string][]byte
+	closed     bool
+}
+
+// NewMemory creates an empty in-memory store, for tests and for running without a database.
+func NewMemory() Store {
+	return &memoryStore{namespaces: make(map[Namespace]map[string][]byte)}
+}
+
+// View runs fn in a read-only transaction.
+func (s *memoryStore) View(fn func(tx Tx) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	return fn(&memoryTx{namespaces: s.namespaces})
+}
+
+// Update runs fn in a read-write transaction.
+func (s *memoryStore) Update(fn func(tx Tx) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	// The transaction shares the committed namespaces until it writes to one.
+	namespaces := make(map[Namespace]map[string][]byte, len(s.namespaces))
+	for ns, keys := range s.namespaces {
+		namespaces[ns] = keys
+	}
+
+	tx := &memoryTx{namespaces: namespaces, copied: make(map[Namespace]bool), writable: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	s.namespaces = namespaces
+
+	return nil
+}
+
+// Close discards the data.
+func (s *memoryStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	s.namespaces = nil
+
+	return nil
+}
+
+type memoryTx struct {
+	namespaces map[Namespace]map[string][]byte
+	// copied holds the namespaces this transaction owns and can modify in place.
+	copied   map[Namespace]bool
+	writable bool
+}
+
+func (t *memoryTx) Bucket(ns Namespace) Bucket {
+	return memoryBucket{tx: t, ns: ns}
+}
+
+func (t *memoryTx) Namespaces() ([]Namespace, error) {
+	namespaces := make([]Namespace, 0, len(t.namespaces))
+	for ns := range t.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+
+	sortNamespaces(namespaces)
+
+	return namespaces, nil
+}
+
+func (t *memoryTx) DeleteNamespace(ns Namespace) error {
+	if !t.writable {
+		return ErrReadOnly
+	}
+
+	delete(t.namespaces, ns)
+	// A later write must not reuse the deleted keys as this transaction's copy.
+	delete(t.copied, ns)
+
+	return nil
+}
+
+// writableKeys returns the keys of ns that this transaction may modify, copying the
+// committed keys first and creating the namespace if needed.
+func (t *memoryTx) writableKeys(ns Namespace) map[string][]byte {
+	if t.copied[ns] {
+		return t.namespaces[ns]
+	}
+
+	keys := make(map[string][]byte, len(t.namespaces[ns]))
+	for key, value := range t.namespaces[ns] {
+		keys[key] = value
+	}
+
+	t.namespaces[ns] = keys
+	t.copied[ns] = true
+
+	return keys
+}
+
+type memoryBucket struct {
+	tx *memoryTx
+	ns Namespace
+}
+
+func (b memoryBucket) Get(key string) ([]byte, error) {
+	value, ok := b.tx.namespaces[b.ns][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return bytes.Clone(value), nil
+}
+
+func (b memoryBucket) Put(key string, value []byte) error {
+	if !b.tx.writable {
+		return ErrReadOnly
+	}
+
+	// Stored values are never modified, so committed copies can share them.
+	stored := bytes.Clone(value)
+	if stored == nil {
+		stored = []byte{}
+	}
+
+	b.tx.writableKeys(b.ns)[key] = stored
+
+	return nil
+}
+
+func (b memoryBucket) Delete(key string) error {
+	if !b.tx.writable {
+		return ErrReadOnly
+	}
+
+	if _, ok := b.tx.namespaces[b.ns][key]; ok {
+		delete(b.tx.writableKeys(b.ns), key)
+	}
+
+	return nil
+}
+
+func (b memoryBucket) Scan(prefix string, fn func(key string, value []byte) error) error {
+	keys := b.tx.namespaces[b.ns]
+
+	matching := make([]string, 0, len(keys))
+	for key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			matching = append(matching, key)
+		}
+	}
+
+	sort.Strings(matching)
+
+	for _, key := range matching {
+		// fn may write to the bucket; skip keys it deleted.
+		value, ok := b.tx.namespaces[b.ns][key]
+		if !ok {
+			continue
+		}
+
+		if err := fn(key, bytes.Clone(value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dunamismax/discogo/errors"
+	"github.com/dunamismax/discogo/logging"
+)
+
+// schemaNamespace holds the schema version of the store.
+const schemaNamespace = Namespace("schema")
+
+// schemaVersionKey is the key of the schema version in schemaNamespace.
+const schemaVersionKey = "version"
+
+// ErrSchemaTooNew is returned when the store was migrated by a newer version of the bot.
+var ErrSchemaTooNew = errors.NewInternalError("storage schema is newer than this version of the bot supports", nil)
+
+// Migration changes the shape of the stored data from one schema version to the next.
+type Migration struct {
+	// Version is the schema version the migration produces. Versions start at 1 and
+	// must be consecutive.
+	Version int
+	// Description says what the migration changes.
+	Description string
+	// Up migrates the data from Version-1 to Version.
+	Up func(tx Tx) error
+	// Down reverts Up. A migration without Down cannot be rolled back.
+	Down func(tx Tx) error
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Backuper is implemented by stores that can write a consistent copy of their data.
+type Backuper interface {
+	// Backup writes a copy of the data that can be opened as a store of the same backend.
+	Backup(w io.Writer) error
+}
+
+// Migrator applies migrations to a store. Every step runs in its own transaction together
+// with the update of the schema version, so an interrupted run leaves the store at the
+// last completed step.
+type Migrator struct {
+	store      Store
+	migrations []Migration
+	backupDir  string
+}
+
+// NewMigrator creates a migrator for store. Before changing a store that supports backups,
+// it writes a backup to backupDir; an empty backupDir disables backups.
+func NewMigrator(store Store, migrations []Migration, backupDir string) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i, migration := range sorted {
+		if migration.Version != i+1 {
+			return nil, errors.NewInternalError(fmt.Sprintf("migration versions must be consecutive from 1, found %d at position %d",
+				migration.Version, i+1), nil)
+		}
+
+		if migration.Up == nil {
+			return nil, errors.NewInternalError(fmt.Sprintf("migration %d has no up step", migration.Version), nil)
+		}
+	}
+
+	return &Migrator{store: store, migrations: sorted, backupDir: backupDir}, nil
+}
+
+// Latest returns the newest schema version the migrations produce.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Version returns the current schema version of the store; 0 for a new store.
+func (m *Migrator) Version() (int, error) {
+	var version int
+
+	err := m.store.View(func(tx Tx) error {
+		var err error
+		version, err = SchemaVersion(tx)
+
+		return err
+	})
+
+	return version, err
+}
+
+// Status returns every known migration and whether it has been applied, with the
+// current schema version, which can be newer than every known migration.
+func (m *Migrator) Status() ([]MigrationStatus, int, error) {
+	version, err := m.Version()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: migration.Version <= version})
+	}
+
+	return statuses, version, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() error {
+	return m.Migrate(m.Latest())
+}
+
+// Migrate applies or rolls back migrations until the store is at the target version.
+// It refuses to touch a store whose schema is newer than the latest known migration.
+func (m *Migrator) Migrate(target int) error {
+	logger := logging.WithComponent("storage")
+
+	if target < 0 || target > m.Latest() {
+		return errors.NewValidationError(fmt.Sprintf("target schema version %d is out of range (0 to %d)", target, m.Latest()))
+	}
+
+	version, err := m.Version()
+	if err != nil {
+		return errors.NewInternalError("failed to read schema version", err)
+	}
+
+	if version > m.Latest() {
+		return errors.NewInternalError(fmt.Sprintf("store is at schema version %d, latest known is %d", version, m.Latest()),
+			ErrSchemaTooNew)
+	}
+
+	if version == target {
+		logger.Debug("Storage schema is up to date", "version", version)
+		return nil
+	}
+
+	// Check that every step can run before changing anything.
+	for v := version; v > target; v-- {
+		if m.migrations[v-1].Down == nil {
+			return errors.NewValidationError(fmt.Sprintf("migration %d (%s) cannot be rolled back",
+				v, m.migrations[v-1].Description))
+		}
+	}
+
+	if err := m.backup(version); err != nil {
+		return err
+	}
+
+	for version < target {
+		migration := m.migrations[version]
+		if err := m.step(migration.Version, migration.Up); err != nil {
+			return errors.NewInternalError(fmt.Sprintf("migration %d (%s) failed", migration.Version, migration.Description),
+				err)
+		}
+
+		logger.Info("Applied storage migration", "version", migration.Version, "description", migration.Description)
+
+		version++
+	}
+
+	for version > target {
+		migration := m.migrations[version-1]
+		if err := m.step(version-1, migration.Down); err != nil {
+			return errors.NewInternalError(fmt.Sprintf("rollback of migration %d (%s) failed", migration.Version,
+				migration.Description), err)
+		}
+
+		logger.Info("Rolled back storage migration", "version", migration.Version, "description", migration.Description)
+
+		version--
+	}
+
+	return nil
+}
+
+// step runs fn and records the resulting schema version in one transaction.
+func (m *Migrator) step(resultVersion int, fn func(tx Tx) error) error {
+	return m.store.Update(func(tx Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		return tx.Bucket(schemaNamespace).Put(schemaVersionKey, []byte(strconv.Itoa(resultVersion)))
+	})
+}
+
+// backup writes a copy of the store before it is migrated from version. New stores, which
+// hold nothing to lose, and stores that do not support backups, such as the in-memory
+// store, are migrated without one.
+func (m *Migrator) backup(version int) error {
+	backuper, ok := m.store.(Backuper)
+	if !ok || m.backupDir == "" || version == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.backupDir, 0o750); err != nil {
+		return errors.NewInternalError("failed to create backup directory", err)
+	}
+
+	path := filepath.Join(m.backupDir, fmt.Sprintf("discogo-v%d-%s.db", version, time.Now().UTC().Format("20060102T150405.000Z")))
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.NewInternalError("failed to create backup file", err)
+	}
+
+	if err := backuper.Backup(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+
+		return errors.NewInternalError("failed to back up storage", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+
+		return errors.NewInternalError("failed to sync backup file", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return errors.NewInternalError("failed to close backup file", err)
+	}
+
+	logging.WithComponent("storage").Info("Backed up storage before migrating", "path", path, "version", version)
+
+	return nil
+}
+
+// SchemaVersion returns the schema version recorded in tx; 0 for a new store.
+func SchemaVersion(tx Tx) (int, error) {
+	data, err := tx.Bucket(schemaNamespace).Get(schemaVersionKey)
+	if err == ErrNotFound {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0, errors.NewInternalError(fmt.Sprintf("invalid schema version %q", data), err)
+	}
+
+	return version, nil
+}
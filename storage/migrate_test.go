@@ -0,0 +1,237 @@
+package storage
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/dunamismax/discogo/errors"
+)
+
+// recordingMigrations returns count migrations that append "up N" and "down N" to steps
+// when they run.
+func recordingMigrations(count int, steps *[]string) []Migration {
+	migrations := make([]Migration, 0, count)
+
+	for version := 1; version <= count; version++ {
+		migrations = append(migrations, Migration{
+			Version:     version,
+			Description: fmt.Sprintf("migration %d", version),
+			Up: func(Tx) error {
+				*steps = append(*steps, fmt.Sprintf("up %d", version))
+				return nil
+			},
+			Down: func(Tx) error {
+				*steps = append(*steps, fmt.Sprintf("down %d", version))
+				return nil
+			},
+		})
+	}
+
+	return migrations
+}
+
+// setSchemaVersion records a schema version in store, as if it was migrated to it.
+func setSchemaVersion(t *testing.T, store Store, version int) {
+	t.Helper()
+
+	err := store.Update(func(tx Tx) error {
+		return tx.Bucket(schemaNamespace).Put(schemaVersionKey, []byte(strconv.Itoa(version)))
+	})
+	if err != nil {
+		t.Fatalf("failed to set schema version: %v", err)
+	}
+}
+
+func TestNewMigratorOrdering(t *testing.T) {
+	up := func(Tx) error { return nil }
+
+	tests := []struct {
+		name     string
+		versions []int
+		wantErr  bool
+	}{
+		{name: "in order", versions: []int{1, 2, 3}},
+		{name: "out of order", versions: []int{3, 1, 2}},
+		{name: "none", versions: nil},
+		{name: "gap", versions: []int{1, 3}, wantErr: true},
+		{name: "duplicate", versions: []int{1, 1, 2}, wantErr: true},
+		{name: "not from 1", versions: []int{2, 3}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations := make([]Migration, 0, len(tt.versions))
+			for _, version := range tt.versions {
+				migrations = append(migrations, Migration{Version: version, Up: up})
+			}
+
+			migrator, err := NewMigrator(NewMemory(), migrations, "")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NewMigrator() succeeded, want an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewMigrator() failed: %v", err)
+			}
+
+			if migrator.Latest() != len(tt.versions) {
+				t.Errorf("Latest() = %d, want %d", migrator.Latest(), len(tt.versions))
+			}
+
+			for i, migration := range migrator.migrations {
+				if migration.Version != i+1 {
+					t.Errorf("migration at position %d has version %d, want %d", i, migration.Version, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMigratorRequiresUp(t *testing.T) {
+	_, err := NewMigrator(NewMemory(), []Migration{{Version: 1}}, "")
+	if err == nil {
+		t.Fatal("NewMigrator() accepted a migration without an up step")
+	}
+}
+
+func TestMigrateOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        int
+		target      int
+		wantSteps   []string
+		wantVersion int
+	}{
+		{name: "new store to latest", from: 0, target: 3, wantSteps: []string{"up 1", "up 2", "up 3"}, wantVersion: 3},
+		{name: "pending only", from: 1, target: 3, wantSteps: []string{"up 2", "up 3"}, wantVersion: 3},
+		{name: "up to target", from: 0, target: 2, wantSteps: []string{"up 1", "up 2"}, wantVersion: 2},
+		{name: "up to date", from: 3, target: 3, wantVersion: 3},
+		{name: "down newest first", from: 3, target: 1, wantSteps: []string{"down 3", "down 2"}, wantVersion: 1},
+		{name: "down to new store", from: 2, target: 0, wantSteps: []string{"down 2", "down 1"}, wantVersion: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemory()
+			setSchemaVersion(t, store, tt.from)
+
+			var steps []string
+
+			migrator, err := NewMigrator(store, recordingMigrations(3, &steps), "")
+			if err != nil {
+				t.Fatalf("NewMigrator() failed: %v", err)
+			}
+
+			if err := migrator.Migrate(tt.target); err != nil {
+				t.Fatalf("Migrate(%d) failed: %v", tt.target, err)
+			}
+
+			if !slices.Equal(steps, tt.wantSteps) {
+				t.Errorf("steps = %v, want %v", steps, tt.wantSteps)
+			}
+
+			version, err := migrator.Version()
+			if err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+
+			if version != tt.wantVersion {
+				t.Errorf("Version() = %d, want %d", version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestMigrateRefusesInvalidTargets(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   int
+		target int
+		noDown bool
+	}{
+		{name: "negative target", from: 0, target: -1},
+		{name: "target beyond latest", from: 0, target: 4},
+		{name: "rollback without down", from: 2, target: 0, noDown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemory()
+			setSchemaVersion(t, store, tt.from)
+
+			var steps []string
+
+			migrations := recordingMigrations(3, &steps)
+			if tt.noDown {
+				migrations[0].Down = nil
+			}
+
+			migrator, err := NewMigrator(store, migrations, "")
+			if err != nil {
+				t.Fatalf("NewMigrator() failed: %v", err)
+			}
+
+			err = migrator.Migrate(tt.target)
+			if err == nil {
+				t.Fatalf("Migrate(%d) succeeded, want an error", tt.target)
+			}
+
+			if !errors.IsErrorType(err, errors.ErrorTypeValidation) {
+				t.Errorf("Migrate(%d) = %v, want a validation error", tt.target, err)
+			}
+
+			if len(steps) > 0 {
+				t.Errorf("Migrate(%d) ran %v before refusing", tt.target, steps)
+			}
+		})
+	}
+}
+
+func TestMigrateRefusesSchemaTooNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		target int
+	}{
+		{name: "up", target: 2},
+		{name: "down", target: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemory()
+			setSchemaVersion(t, store, 3)
+
+			var steps []string
+
+			migrator, err := NewMigrator(store, recordingMigrations(2, &steps), "")
+			if err != nil {
+				t.Fatalf("NewMigrator() failed: %v", err)
+			}
+
+			err = migrator.Migrate(tt.target)
+			if !stdErrors.Is(err, ErrSchemaTooNew) {
+				t.Fatalf("Migrate(%d) = %v, want ErrSchemaTooNew", tt.target, err)
+			}
+
+			if len(steps) > 0 {
+				t.Errorf("Migrate(%d) ran %v on a newer store", tt.target, steps)
+			}
+
+			version, err := migrator.Version()
+			if err != nil {
+				t.Fatalf("Version() failed: %v", err)
+			}
+
+			if version != 3 {
+				t.Errorf("Version() = %d after refusing, want 3", version)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Package migrations holds the schema migrations of the bot's storage.
+//
+// Add a migration for every change to the shape of stored data, with the next version
+// number. Never change a migration that has been released: stores in the field have
+// already applied it.
+package migrations
+
+import (
+	"time"
+
+	"github.com/dunamismax/discogo/storage"
+)
+
+// metaNamespace holds information about the store itself.
+var metaNamespace = storage.FeatureNamespace("meta")
+
+// All is every migration, oldest first.
+var All = []storage.Migration{
+	{
+		Version:     1,
+		Description: "record when the store was created",
+		Up: func(tx storage.Tx) error {
+			return tx.Bucket(metaNamespace).Put("created_at", []byte(time.Now().UTC().Format(time.RFC3339)))
+		},
+		Down: func(tx storage.Tx) error {
+			return tx.DeleteNamespace(metaNamespace)
+		},
+	},
+}
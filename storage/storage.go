@@ -0,0 +1,126 @@
+// Package storage provides transactional key-value and document storage for the bot's
+// persistent features, with an embedded bbolt backend and an in-memory backend for tests.
+//
+// Data is split into namespaces, one per feature and, for per-guild data, per guild:
+//
+//	err := store.Update(func(tx storage.Tx) error {
+//		return tx.Bucket(storage.GuildNamespace("settings", guildID)).Put("prefix", []byte("?"))
+//	})
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dunamismax/discogo/errors"
+)
+
+// Supported backends.
+const (
+	// BackendBolt stores data in a single bbolt database file.
+	BackendBolt = "bolt"
+	// BackendMemory keeps data in memory only; it is lost when the bot stops.
+	BackendMemory = "memory"
+)
+
+// Backends lists the supported backends.
+var Backends = []string{BackendBolt, BackendMemory}
+
+var (
+	// ErrNotFound is returned by Get when a key does not exist.
+	ErrNotFound = errors.NewNotFoundError("key not found")
+	// ErrReadOnly is returned when writing in a transaction started with View.
+	ErrReadOnly = errors.NewInternalError("write in a read-only transaction", nil)
+	// ErrClosed is returned when starting a transaction on a closed in-memory store.
+	ErrClosed = errors.NewInternalError("store is closed", nil)
+)
+
+// Store is a transactional key-value store. It is safe for concurrent use.
+type Store interface {
+	// View runs fn in a read-only transaction.
+	View(fn func(tx Tx) error) error
+	// Update runs fn in a read-write transaction. The changes are committed when fn
+	// returns nil and discarded when it returns an error.
+	Update(fn func(tx Tx) error) error
+	// Close releases the store. Transactions cannot be started afterwards.
+	Close() error
+}
+
+// Tx is a transaction. It must not be used after the function it was passed to returns.
+type Tx interface {
+	// Bucket returns the keys of a namespace. Namespaces are created by their first write;
+	// reading a namespace that does not exist behaves as if it were empty.
+	Bucket(ns Namespace) Bucket
+	// Namespaces returns every namespace that exists, sorted by name.
+	Namespaces() ([]Namespace, error)
+	// DeleteNamespace deletes a namespace and all its keys.
+	DeleteNamespace(ns Namespace) error
+}
+
+// Bucket holds the keys of one namespace within a transaction.
+type Bucket interface {
+	// Get returns the value of key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Put sets the value of key.
+	Put(key string, value []byte) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// Scan calls fn for every key starting with prefix, in key order, until fn returns an error.
+	Scan(prefix string, fn func(key string, value []byte) error) error
+}
+
+// Namespace names an isolated set of keys, such as the data of one feature or of one
+// feature in one guild.
+type Namespace string
+
+// namespaceSeparator separates the feature and the guild ID of a namespace.
+const namespaceSeparator = "/"
+
+// FeatureNamespace returns the global namespace of a feature. Feature names must not contain "/".
+func FeatureNamespace(feature string) Namespace {
+	return Namespace(feature)
+}
+
+// GuildNamespace returns the namespace of a feature's data for one guild.
+func GuildNamespace(feature, guildID string) Namespace {
+	return Namespace(feature + namespaceSeparator + guildID)
+}
+
+// Feature returns the feature the namespace belongs to.
+func (ns Namespace) Feature() string {
+	feature, _, _ := strings.Cut(string(ns), namespaceSeparator)
+	return feature
+}
+
+// GuildID returns the guild of a per-guild namespace, or an empty string for a global one.
+func (ns Namespace) GuildID() string {
+	_, guildID, _ := strings.Cut(string(ns), namespaceSeparator)
+	return guildID
+}
+
+// Config selects and configures a backend.
+type Config struct {
+	// Backend is BackendBolt or BackendMemory.
+	Backend string
+	// Path is the database file of the bolt backend.
+	Path string
+}
+
+// Open opens the store selected by config.
+func Open(config Config) (Store, error) {
+	switch config.Backend {
+	case BackendBolt:
+		return OpenBolt(config.Path)
+	case BackendMemory:
+		return NewMemory(), nil
+	default:
+		return nil, errors.NewConfigError(fmt.Sprintf("unknown storage backend %q (valid: %s)",
+			config.Backend, strings.Join(Backends, ", ")), nil)
+	}
+}
+
+// sortNamespaces sorts namespaces by name.
+func sortNamespaces(namespaces []Namespace) {
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i] < namespaces[j] })
+}
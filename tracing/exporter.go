@@ -0,0 +1,196 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/dunamismax/discogo/logging"
+)
+
+// instrumentationScope names this package as the producer of exported spans.
+const instrumentationScope = "github.com/dunamismax/discogo/tracing"
+
+// exporter sends spans to an OTLP/HTTP collector using the JSON encoding.
+type exporter struct {
+	config Config
+	client *http.Client
+}
+
+func newExporter(config Config) *exporter {
+	return &exporter{
+		config: config,
+		// Export requests must not be traced themselves, so use a plain transport.
+		client: &http.Client{Timeout: config.ExportTimeout},
+	}
+}
+
+// OTLP/JSON payload types; see opentelemetry-proto's trace/v1 and common/v1 definitions.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              SpanKind       `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+
+	otlpStatus struct {
+		Code    StatusCode `json:"code"`
+		Message string     `json:"message,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// export sends one batch of spans. Failures are logged and the batch is dropped,
+// because tracing must never hold up the bot.
+func (e *exporter) export(spans []*Span, dropped int64) {
+	logger := logging.WithComponent("tracing")
+
+	if dropped > 0 {
+		logger.Warn("Dropped spans because the export queue was full", "dropped", dropped)
+	}
+
+	payload := otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{keyValue(String("service.name", e.config.ServiceName))},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: instrumentationScope},
+				Spans: make([]otlpSpan, 0, len(spans)),
+			}},
+		}},
+	}
+
+	scopeSpans := &payload.ResourceSpans[0].ScopeSpans[0]
+	for _, span := range spans {
+		scopeSpans.Spans = append(scopeSpans.Spans, toOTLP(span))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error("Failed to encode spans", "error", err)
+		return
+	}
+
+	if err := e.post(body); err != nil {
+		logger.Warn("Failed to export spans", "error", err, "spans", len(spans))
+	}
+}
+
+func (e *exporter) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.ExportTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create export request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send export request: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector responded with status %s", resp.Status)
+	}
+
+	return nil
+}
+
+func toOTLP(span *Span) otlpSpan {
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	out := otlpSpan{
+		TraceID:           span.traceID.String(),
+		SpanID:            span.spanID.String(),
+		Name:              span.name,
+		Kind:              span.kind,
+		StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: span.status, Message: span.statusMsg},
+	}
+
+	if span.parentID != (SpanID{}) {
+		out.ParentSpanID = span.parentID.String()
+	}
+
+	for _, attribute := range span.attributes {
+		out.Attributes = append(out.Attributes, keyValue(attribute))
+	}
+
+	return out
+}
+
+func keyValue(attribute Attribute) otlpKeyValue {
+	kv := otlpKeyValue{Key: attribute.Key}
+
+	switch value := attribute.Value.(type) {
+	case string:
+		kv.Value.StringValue = &value
+	case bool:
+		kv.Value.BoolValue = &value
+	case int64:
+		formatted := strconv.FormatInt(value, 10)
+		kv.Value.IntValue = &formatted
+	case float64:
+		kv.Value.DoubleValue = &value
+	default:
+		formatted := fmt.Sprint(value)
+		kv.Value.StringValue = &formatted
+	}
+
+	return kv
+}
@@ -0,0 +1,465 @@
+// Package tracing provides lightweight distributed tracing with OTLP/HTTP export.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the lowercase hex encoding of the trace ID.
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// String returns the lowercase hex encoding of the span ID.
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanKind describes the relationship of a span to its callers and callees.
+type SpanKind int
+
+// Span kinds, numbered as in the OTLP protocol.
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// StatusCode is the final status of a span.
+type StatusCode int
+
+// Status codes, numbered as in the OTLP protocol.
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Attribute is a key/value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String creates a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int creates an integer attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool creates a boolean attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is a single timed operation within a trace.
+// All methods are safe to call on a nil span, which makes disabled tracing free for callers.
+type Span struct {
+	provider   *Provider
+	name       string
+	kind       SpanKind
+	traceID    TraceID
+	spanID     SpanID
+	parentID   SpanID
+	sampled    bool
+	start      time.Time
+	end        time.Time
+	attributes []Attribute
+	status     StatusCode
+	statusMsg  string
+	ended      bool
+	mutex      sync.Mutex
+}
+
+// TraceID returns the ID of the trace the span belongs to.
+func (s *Span) TraceID() TraceID {
+	if s == nil {
+		return TraceID{}
+	}
+
+	return s.traceID
+}
+
+// SpanID returns the ID of the span.
+func (s *Span) SpanID() SpanID {
+	if s == nil {
+		return SpanID{}
+	}
+
+	return s.spanID
+}
+
+// IsSampled reports whether the span will be exported.
+func (s *Span) IsSampled() bool {
+	return s != nil && s.sampled
+}
+
+// SetName replaces the name of the span.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.name = name
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil || !s.sampled {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.attributes = append(s.attributes, attributes...)
+}
+
+// RecordError marks the span as failed with the error message.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.SetStatus(StatusError, err.Error())
+}
+
+// SetStatus sets the final status of the span.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.status = code
+	s.statusMsg = message
+}
+
+// End completes the span and queues it for export if it is sampled.
+// Calling End more than once has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.end = time.Now()
+	s.mutex.Unlock()
+
+	if s.sampled && s.provider != nil {
+		s.provider.enqueue(s)
+	}
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpan returns a copy of ctx that carries span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+
+	return span
+}
+
+// Config configures a Provider.
+type Config struct {
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// Endpoint is the OTLP/HTTP traces URL, for example http://localhost:4318/v1/traces.
+	Endpoint string
+	// SampleRatio is the fraction of new traces that are exported, between 0 and 1.
+	SampleRatio float64
+	// BatchSize is the maximum number of spans sent in one export request.
+	BatchSize int
+	// ExportInterval is how often queued spans are exported.
+	ExportInterval time.Duration
+	// ExportTimeout bounds a single export request.
+	ExportTimeout time.Duration
+}
+
+// Provider creates spans and exports the sampled ones.
+type Provider struct {
+	config    Config
+	exporter  *exporter
+	threshold uint64
+	queue     chan *Span
+	flush     chan chan struct{}
+	stop      chan struct{}
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+	dropped   atomic.Int64
+}
+
+// Default export settings.
+const (
+	defaultBatchSize      = 512
+	defaultExportInterval = 5 * time.Second
+	defaultExportTimeout  = 10 * time.Second
+	queueCapacity         = 2048
+)
+
+// NewProvider creates a provider and starts its background exporter.
+func NewProvider(config Config) (*Provider, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("tracing endpoint is required")
+	}
+
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio must be between 0 and 1, got %v", config.SampleRatio)
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+
+	if config.ExportInterval <= 0 {
+		config.ExportInterval = defaultExportInterval
+	}
+
+	if config.ExportTimeout <= 0 {
+		config.ExportTimeout = defaultExportTimeout
+	}
+
+	p := &Provider{
+		config:    config,
+		exporter:  newExporter(config),
+		threshold: ratioThreshold(config.SampleRatio),
+		queue:     make(chan *Span, queueCapacity),
+		flush:     make(chan chan struct{}),
+		stop:      make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+
+	go p.run()
+
+	return p, nil
+}
+
+// ratioThreshold converts a sample ratio to a threshold on the low 63 bits of a trace ID.
+func ratioThreshold(ratio float64) uint64 {
+	if ratio >= 1 {
+		return 1 << 63
+	}
+
+	return uint64(ratio * (1 << 63))
+}
+
+// shouldSample makes the sampling decision for a new trace deterministically from its ID,
+// so every service sampling at the same ratio agrees.
+func (p *Provider) shouldSample(traceID TraceID) bool {
+	return binary.BigEndian.Uint64(traceID[8:])>>1 < p.threshold
+}
+
+// Start creates a span as a child of the span in ctx, or as the root of a new trace.
+func (p *Provider) Start(ctx context.Context, name string, kind SpanKind, attributes ...Attribute) (context.Context, *Span) {
+	span := &Span{
+		provider: p,
+		name:     name,
+		kind:     kind,
+		start:    time.Now(),
+	}
+
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+		span.sampled = parent.sampled
+	} else {
+		span.traceID = newTraceID()
+		span.sampled = p.shouldSample(span.traceID)
+	}
+
+	span.spanID = newSpanID()
+
+	if span.sampled {
+		span.attributes = append(span.attributes, attributes...)
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// enqueue queues an ended span for export, dropping it when the queue is full.
+func (p *Provider) enqueue(span *Span) {
+	select {
+	case p.queue <- span:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// run exports queued spans in batches until the provider is shut down.
+func (p *Provider) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.ExportInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, p.config.BatchSize)
+
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		p.exporter.export(batch, p.dropped.Swap(0))
+		batch = make([]*Span, 0, p.config.BatchSize)
+	}
+
+	drain := func() {
+		for {
+			select {
+			case span := <-p.queue:
+				batch = append(batch, span)
+				if len(batch) >= p.config.BatchSize {
+					export()
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			if len(batch) >= p.config.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case done := <-p.flush:
+			drain()
+			export()
+			close(done)
+		case <-p.stop:
+			drain()
+			export()
+
+			return
+		}
+	}
+}
+
+// ForceFlush exports all queued spans before returning or until ctx is done.
+func (p *Provider) ForceFlush(ctx context.Context) error {
+	done := make(chan struct{})
+
+	select {
+	case p.flush <- done:
+	case <-ctx.Done():
+		return fmt.Errorf("tracing flush aborted: %w", ctx.Err())
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("tracing flush aborted: %w", ctx.Err())
+	}
+}
+
+// Shutdown exports remaining spans and stops the background exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	done := make(chan struct{})
+
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("tracing shutdown aborted: %w", ctx.Err())
+	}
+}
+
+// LogAttrs returns the trace and span IDs of the span in ctx as log attributes,
+// so log records can be correlated with traces.
+func LogAttrs(ctx context.Context) []slog.Attr {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return nil
+	}
+
+	return []slog.Attr{
+		slog.String("trace_id", span.TraceID().String()),
+		slog.String("span_id", span.SpanID().String()),
+	}
+}
+
+var globalProvider atomic.Pointer[Provider]
+
+// SetProvider installs the provider used by the package-level Start function.
+func SetProvider(p *Provider) {
+	globalProvider.Store(p)
+}
+
+// Start creates a span using the global provider. When no provider is installed
+// it returns ctx unchanged and a nil span, whose methods are no-ops.
+func Start(ctx context.Context, name string, kind SpanKind, attributes ...Attribute) (context.Context, *Span) {
+	p := globalProvider.Load()
+	if p == nil {
+		return ctx, nil
+	}
+
+	return p.Start(ctx, name, kind, attributes...)
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+
+	_, _ = rand.Read(id[:]) // crypto/rand.Read never returns an error.
+
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+
+	_, _ = rand.Read(id[:]) // crypto/rand.Read never returns an error.
+
+	return id
+}
@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// snowflakePattern matches a URL path segment that is a Discord ID.
+var snowflakePattern = regexp.MustCompile(`^\d{15,21}$`)
+
+// tokenRoutes are the path segments whose ID is followed by a secret token, as in
+// /webhooks/{id}/{token} and /interactions/{id}/{token}/callback.
+var tokenRoutes = map[string]bool{
+	"webhooks":     true,
+	"interactions": true,
+}
+
+// Transport is an http.RoundTripper that creates a client span for every request,
+// as a child of the span carried by the request context.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base, or http.DefaultTransport when base is nil, with tracing.
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{Base: base}
+}
+
+// RoundTrip executes a single HTTP transaction inside a client span.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route := redactPath(req.URL.Path)
+
+	_, span := Start(req.Context(), "HTTP "+req.Method+" "+route, SpanKindClient,
+		String("http.request.method", req.Method),
+		String("server.address", req.URL.Hostname()),
+		String("url.path", route),
+	)
+	defer span.End()
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		// Transport errors quote the URL, tokens included.
+		span.SetStatus(StatusError, scrubURL(err.Error(), req, route))
+		return nil, fmt.Errorf("traced request failed: %w", err)
+	}
+
+	span.SetAttributes(Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(StatusError, scrubURL(resp.Status, req, route))
+	}
+
+	return resp, nil
+}
+
+// redactPath replaces IDs in a URL path with {id}, so span names stay low-cardinality and
+// group by route, and every other segment after a webhook or interaction ID with {token},
+// so their secret tokens never leave the bot.
+func redactPath(path string) string {
+	segments := strings.Split(path, "/")
+	secret := false
+
+	for i, segment := range segments {
+		switch {
+		case snowflakePattern.MatchString(segment):
+			secret = secret || (i > 0 && tokenRoutes[segments[i-1]])
+			segments[i] = "{id}"
+		case secret && segment != "":
+			segments[i] = "{token}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// scrubURL replaces the request's URL and path in message with the redacted route.
+func scrubURL(message string, req *http.Request, route string) string {
+	if req.URL.Path == "" {
+		return message
+	}
+
+	redacted := req.URL.Scheme + "://" + req.URL.Host + route
+
+	message = strings.ReplaceAll(message, req.URL.String(), redacted)
+	message = strings.ReplaceAll(message, req.URL.EscapedPath(), route)
+
+	return strings.ReplaceAll(message, req.URL.Path, route)
+}
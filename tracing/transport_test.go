@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testToken = "aW50ZXJhY3Rpb246c2VjcmV0LXRva2Vu"
+
+func TestRedactPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v10/channels/123456789012345678/messages", "/api/v10/channels/{id}/messages"},
+		{"/api/v10/webhooks/123456789012345678/" + testToken, "/api/v10/webhooks/{id}/{token}"},
+		{
+			"/api/v10/webhooks/123456789012345678/" + testToken + "/messages/@original",
+			"/api/v10/webhooks/{id}/{token}/{token}/{token}",
+		},
+		{
+			"/api/v10/webhooks/123456789012345678/" + testToken + "/messages/234567890123456789",
+			"/api/v10/webhooks/{id}/{token}/{token}/{id}",
+		},
+		{"/api/v10/interactions/123456789012345678/" + testToken + "/callback", "/api/v10/interactions/{id}/{token}/{token}"},
+		{"/api/v10/webhooks/123456789012345678", "/api/v10/webhooks/{id}"},
+	}
+
+	for _, test := range tests {
+		if got := redactPath(test.path); got != test.want {
+			t.Errorf("redactPath(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransportExportsNoTokens(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		exported []string
+	)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mutex.Lock()
+		exported = append(exported, string(body))
+		mutex.Unlock()
+	}))
+	defer collector.Close()
+
+	discord := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, "Unknown Webhook "+r.URL.Path)
+	}))
+	defer discord.Close()
+
+	provider, err := NewProvider(Config{
+		ServiceName:    "test",
+		Endpoint:       collector.URL,
+		SampleRatio:    1,
+		ExportInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetProvider(provider)
+	defer SetProvider(nil)
+
+	ctx := context.Background()
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport}}
+
+	// A response with an error status.
+	resp, err := client.Post(discord.URL+"/api/v10/webhooks/123456789012345678/"+testToken+"?wait=true", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = resp.Body.Close()
+
+	// A transport error that quotes the URL.
+	failing := &http.Client{Transport: &Transport{Base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection reset while requesting " + req.URL.String())
+	})}}
+
+	if _, err := failing.Post(discord.URL+"/api/v10/interactions/123456789012345678/"+testToken+"/callback", "", nil); err == nil {
+		t.Fatal("expected the failing request to fail")
+	}
+
+	if err := provider.ForceFlush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := provider.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	payload := strings.Join(exported, "\n")
+	mutex.Unlock()
+
+	if !strings.Contains(payload, "/api/v10/webhooks/{id}/{token}") {
+		t.Errorf("exported spans do not contain the redacted webhook route: %s", payload)
+	}
+
+	if !strings.Contains(payload, "/api/v10/interactions/{id}/{token}/{token}") {
+		t.Errorf("exported spans do not contain the redacted interaction route: %s", payload)
+	}
+
+	if strings.Contains(payload, testToken) {
+		t.Errorf("exported spans contain the token: %s", payload)
+	}
+}